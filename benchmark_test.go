@@ -3,6 +3,7 @@
 package schema_test
 
 import (
+	"strconv"
 	"strings"
 	"testing"
 
@@ -186,3 +187,172 @@ func BenchmarkParseAndMakeValidator(b *testing.B) {
 		v.Compile() // force compiling for comparison
 	}
 }
+
+// patternSchemaJSON returns a schema whose properties use the
+// patterns in `patterns`
+func patternSchemaJSON(patterns ...string) string {
+	props := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		props[i] = `"p` + strconv.Itoa(i) + `": { "type": "string", "pattern": "` + pattern + `" }`
+	}
+	return `{ "type": "object", "properties": { ` + strings.Join(props, ", ") + ` } }`
+}
+
+// Reading many schemas that use the same patterns only compiles
+// each pattern once
+func BenchmarkParseSharedPatterns(b *testing.B) {
+	srcs := make([]string, 1000)
+	for i := range srcs {
+		srcs[i] = patternSchemaJSON(`^[a-zA-Z][a-zA-Z0-9_-]*$`, `^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, src := range srcs {
+			if _, err := schema.Read(strings.NewReader(src)); err != nil {
+				b.Fatalf("schema.Read failed: %s", err)
+			}
+		}
+	}
+}
+
+// For comparison, every schema uses patterns that were never seen
+// before, so they all have to be compiled
+func BenchmarkParseDistinctPatterns(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		srcs := make([]string, 1000)
+		for j := range srcs {
+			n := strconv.Itoa(i*len(srcs) + j)
+			srcs[j] = patternSchemaJSON(`^[a-zA-Z][a-zA-Z0-9_-]*`+n+`$`, `^[0-9]{4}-[0-9]{2}-[0-9]{2}`+n+`$`)
+		}
+		b.StartTimer()
+		for _, src := range srcs {
+			if _, err := schema.Read(strings.NewReader(src)); err != nil {
+				b.Fatalf("schema.Read failed: %s", err)
+			}
+		}
+	}
+}
+
+const refSchemaJSON = `{
+  "definitions": {
+    "name": { "type": "string", "minLength": 1 },
+    "positive": { "type": "integer", "minimum": 0 },
+    "address": {
+      "type": "object",
+      "properties": {
+        "street": { "$ref": "#/definitions/name" },
+        "city": { "$ref": "#/definitions/name" },
+        "number": { "$ref": "#/definitions/positive" }
+      }
+    }
+  },
+  "type": "object",
+  "properties": {
+    "name": { "$ref": "#/definitions/name" },
+    "age": { "$ref": "#/definitions/positive" },
+    "home": { "$ref": "#/definitions/address" },
+    "work": { "$ref": "#/definitions/address" }
+  }
+}`
+
+func refObjects(n int) []interface{} {
+	l := make([]interface{}, n)
+	for i := range l {
+		addr := map[string]interface{}{
+			"street": "Main Street",
+			"city":   "Springfield",
+			"number": float64(i),
+		}
+		l[i] = map[string]interface{}{
+			"name": "John",
+			"age":  float64(i % 100),
+			"home": addr,
+			"work": addr,
+		}
+	}
+	return l
+}
+
+// References are resolved once, when the validator is compiled
+func BenchmarkValidateRefs(b *testing.B) {
+	s, err := schema.Read(strings.NewReader(refSchemaJSON))
+	if err != nil {
+		b.Fatalf("schema.Read failed: %s", err)
+	}
+	objects := refObjects(10000)
+	v := validator.New(s)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, o := range objects {
+			if err := v.Validate(o); err != nil {
+				b.Fatalf("Validate failed: %s", err)
+			}
+		}
+	}
+}
+
+// For comparison: compiling, and thus resolving references, for
+// every object validated
+func BenchmarkValidateRefsRecompile(b *testing.B) {
+	s, err := schema.Read(strings.NewReader(refSchemaJSON))
+	if err != nil {
+		b.Fatalf("schema.Read failed: %s", err)
+	}
+	objects := refObjects(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, o := range objects {
+			if err := validator.New(s).Validate(o); err != nil {
+				b.Fatalf("Validate failed: %s", err)
+			}
+		}
+	}
+}
+
+// oneOfSchemaJSON has 10 branches that can be told apart by the type
+// of the value alone: 5 primitive types, and 5 objects that each
+// require a different property
+const oneOfSchemaJSON = `{
+  "oneOf": [
+    { "type": "string" },
+    { "type": "boolean" },
+    { "type": "null" },
+    { "type": "array" },
+    { "type": "integer" },
+    { "type": "object", "required": [ "a" ], "additionalProperties": false, "properties": { "a": {} } },
+    { "type": "object", "required": [ "b" ], "additionalProperties": false, "properties": { "b": {} } },
+    { "type": "object", "required": [ "c" ], "additionalProperties": false, "properties": { "c": {} } },
+    { "type": "object", "required": [ "d" ], "additionalProperties": false, "properties": { "d": {} } },
+    { "type": "object", "required": [ "e" ], "additionalProperties": false, "properties": { "e": {} } }
+  ]
+}`
+
+// go-jsval tries every branch of oneOf in full, including the ones
+// whose type can never match. This is the baseline for pruning them
+func BenchmarkValidateOneOf(b *testing.B) {
+	s, err := schema.Read(strings.NewReader(oneOfSchemaJSON))
+	if err != nil {
+		b.Fatalf("schema.Read failed: %s", err)
+	}
+	values := []interface{}{
+		"string",
+		true,
+		nil,
+		[]interface{}{1.0},
+		float64(42),
+		map[string]interface{}{"a": 1.0},
+		map[string]interface{}{"e": 1.0},
+	}
+	v := validator.New(s)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, x := range values {
+			if err := v.Validate(x); err != nil {
+				b.Fatalf("Validate failed: %s", err)
+			}
+		}
+	}
+}
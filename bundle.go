@@ -0,0 +1,224 @@
+package schema
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Bundle returns a self-contained copy of this schema, in which every
+// `$ref` is a JSON pointer within the copy. References are resolved
+// with Resolve, so ids, anchors, the registry and the reference
+// resolver are all honored. Schemas that live outside of this schema,
+// such as schemas from other documents, are copied into
+// "definitions", under a name derived from the URI they were referred
+// to with, such as "person.json_name" for
+// "http://example.com/person.json#/definitions/name". Ids are
+// removed from the copy, as they would change the meaning of the
+// rewritten references.
+//
+// Bundle may also be called on a subschema, in which case references
+// are still resolved against the document it was read from, and the
+// schemas they point to are copied into the bundle. This allows
+// validating against a part of a larger document.
+//
+// If this is a root schema, every reference in it already is a JSON
+// pointer within the document, and no subschema declares an id, the
+// schema itself is returned.
+func (s *Schema) Bundle() (*Schema, error) {
+	if s.isBundled() {
+		return s, nil
+	}
+
+	root, err := schemaToMap(s)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bundler{
+		root:      root,
+		locations: make(map[*Schema]string),
+	}
+	b.register(s, "#")
+	queue := []bundleEntry{{s, "#"}}
+	for len(queue) > 0 {
+		e := queue[0]
+		queue = queue[1:]
+
+		var err error
+		e.schema.walk("#", func(ptr string, v *Schema) bool {
+			var added []bundleEntry
+			added, err = b.rewrite(e.ptr+ptr[1:], v)
+			queue = append(queue, added...)
+			return err == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	bundled := New()
+	if err := bundled.Extract(b.root); err != nil {
+		return nil, errors.Wrap(err, "failed to extract bundled schema")
+	}
+	return bundled, nil
+}
+
+// isBundled returns true if this is a root schema that only has
+// references that are JSON pointers within the document, and no
+// subschema declares an id
+func (s *Schema) isBundled() bool {
+	if s.parent != nil {
+		return false
+	}
+	return s.Walk(func(v *Schema) bool {
+		if v != s && v.ID != "" {
+			return false
+		}
+		ref := v.Reference
+		return ref == "" || ref == "#" || strings.HasPrefix(ref, "#/")
+	})
+}
+
+type bundleEntry struct {
+	schema *Schema
+	ptr    string
+}
+
+type bundler struct {
+	root      map[string]interface{}
+	locations map[*Schema]string
+}
+
+// register records the location of `s` and its subschemas within
+// the bundle, unless they have been copied there already
+func (b *bundler) register(s *Schema, ptr string) {
+	s.walk("#", func(rel string, v *Schema) bool {
+		if _, ok := b.locations[v]; !ok {
+			b.locations[v] = ptr + rel[1:]
+		}
+		return true
+	})
+}
+
+// rewrite updates the copy of `v` found at `ptr` within the bundle,
+// and returns the schemas that had to be copied into the bundle to
+// resolve its reference
+func (b *bundler) rewrite(ptr string, v *Schema) ([]bundleEntry, error) {
+	m, err := lookupMap(b.root, ptr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to find %s in bundle", strconv.Quote(ptr))
+	}
+	delete(m, "id")
+
+	if v.Reference == "" {
+		return nil, nil
+	}
+
+	target, err := v.Resolve(nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to bundle reference at %s", strconv.Quote(ptr))
+	}
+
+	var added []bundleEntry
+	loc, ok := b.locations[target]
+	if !ok {
+		tm, err := schemaToMap(target)
+		if err != nil {
+			return nil, err
+		}
+
+		defs, ok := b.root["definitions"].(map[string]interface{})
+		if !ok {
+			defs = make(map[string]interface{})
+			b.root["definitions"] = defs
+		}
+		name := definitionName(v.Reference)
+		if u, err := v.ResolveURL(v.Reference); err == nil {
+			name = definitionName(u.String())
+		}
+		key := name
+		for i := 2; defs[key] != nil; i++ {
+			key = name + "-" + strconv.Itoa(i)
+		}
+		defs[key] = tm
+
+		loc = "#/definitions" + ptrToken(key)
+		b.register(target, loc)
+		added = append(added, bundleEntry{target, loc})
+	}
+	m["$ref"] = loc
+	return added, nil
+}
+
+// definitionName returns the name of the definition that holds a
+// copy of the schema identified by `uri`. It is made of the last path
+// segment and the last fragment segment of `uri`, and only holds
+// characters that need no escaping in a JSON pointer
+func definitionName(uri string) string {
+	var fragment string
+	if i := strings.IndexByte(uri, '#'); i >= 0 {
+		uri, fragment = uri[:i], uri[i+1:]
+	}
+	uri = strings.TrimSuffix(uri, "/")
+	doc := uri[strings.LastIndexByte(uri, '/')+1:]
+	fragment = fragment[strings.LastIndexByte(fragment, '/')+1:]
+
+	name := strings.Trim(doc+"_"+fragment, "_")
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" {
+		return "schema"
+	}
+	return name
+}
+
+func schemaToMap(s *Schema) (map[string]interface{}, error) {
+	buf, err := json.Marshal(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal schema")
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal schema")
+	}
+	return m, nil
+}
+
+var ptrUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// lookupMap returns the object pointed to by the JSON pointer
+// fragment `ptr` within `v`
+func lookupMap(v interface{}, ptr string) (map[string]interface{}, error) {
+	ptr = strings.TrimPrefix(ptr, "#")
+	if ptr != "" {
+		for _, token := range strings.Split(ptr[1:], "/") {
+			token = ptrUnescaper.Replace(token)
+			switch val := v.(type) {
+			case map[string]interface{}:
+				v = val[token]
+			case []interface{}:
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 || i >= len(val) {
+					return nil, errors.Errorf("invalid array index %s", strconv.Quote(token))
+				}
+				v = val[i]
+			default:
+				return nil, errors.Errorf("cannot look up %s in %T", strconv.Quote(token), v)
+			}
+		}
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected object, got %T", v)
+	}
+	return m, nil
+}
@@ -0,0 +1,238 @@
+package schema_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat/go-jsschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundle(t *testing.T) {
+	const person = `{
+  "id": "http://example.com/person.json",
+  "definitions": {
+    "name": { "type": "string", "minLength": 1 },
+    "tagged": { "$anchor": "tagged", "type": "array", "items": { "$ref": "#/definitions/name" } }
+  }
+}`
+	const team = `{
+  "id": "http://example.com/team.json",
+  "definitions": {
+    "size": { "$anchor": "size", "type": "integer" },
+    "nested": {
+      "id": "nested/",
+      "definitions": {
+        "code": { "type": "boolean" }
+      },
+      "properties": {
+        "code": { "$ref": "#/definitions/code" }
+      }
+    }
+  },
+  "type": "object",
+  "properties": {
+    "name": { "$ref": "person.json#/definitions/name" },
+    "tags": { "$ref": "http://example.com/person.json#tagged" },
+    "size": { "$ref": "#size" },
+    "nested": { "$ref": "nested/" },
+    "self": { "$ref": "#" }
+  }
+}`
+
+	r := schema.NewRegistry()
+	var schemas []*schema.Schema
+	for _, src := range []string{person, team} {
+		s, err := schema.Read(strings.NewReader(src))
+		if !assert.NoError(t, err, "schema.Read should succeed") {
+			return
+		}
+		if !assert.NoError(t, r.Add(s), "Registry.Add should succeed") {
+			return
+		}
+		schemas = append(schemas, s)
+	}
+
+	s := schemas[1]
+	b, err := s.Bundle()
+	if !assert.NoError(t, err, "Bundle should succeed") {
+		return
+	}
+	if !assert.Empty(t, b.ID, "bundled schema should not have an id") {
+		return
+	}
+	for _, ref := range b.References() {
+		if !assert.True(t, ref == "#" || strings.HasPrefix(ref, "#/"), "reference %s should be a JSON pointer", ref) {
+			return
+		}
+	}
+
+	for name := range b.Definitions {
+		if !assert.False(t, strings.ContainsAny(name, "/#~"), "definition name %s should not need escaping", name) {
+			return
+		}
+	}
+	if !assert.Contains(t, b.Definitions, "person.json_name", "definition name should be derived from the reference") {
+		return
+	}
+
+	// The bundle is a document of its own
+	buf, err := json.Marshal(b)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	b, err = schema.Read(bytes.NewReader(buf))
+	if !assert.NoError(t, err, "schema.Read should succeed for the bundle") {
+		return
+	}
+
+	types := map[string]schema.PrimitiveType{
+		"name":   schema.StringType,
+		"tags":   schema.ArrayType,
+		"size":   schema.IntegerType,
+		"nested": schema.ObjectType,
+		"self":   schema.ObjectType,
+	}
+	for name, typ := range types {
+		resolved, err := b.Properties[name].Resolve(nil)
+		if !assert.NoError(t, err, "Resolve should succeed for '%s'", name) {
+			return
+		}
+		if name == "nested" {
+			// No type on the nested schema itself, check what it refers to
+			resolved, err = resolved.Properties["code"].Resolve(nil)
+			if !assert.NoError(t, err, "Resolve should succeed for nested code") {
+				return
+			}
+			typ = schema.BooleanType
+		}
+		if !assert.Equal(t, schema.PrimitiveTypes{typ}, resolved.Type, "bundled reference '%s' should point to the same schema", name) {
+			return
+		}
+	}
+
+	tags, err := b.Properties["tags"].Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed") {
+		return
+	}
+	item, err := tags.Items.Schemas[0].Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed for copied references") {
+		return
+	}
+	if !assert.Equal(t, 1, item.MinLength.Val, "copied reference should point within the copy") {
+		return
+	}
+}
+
+func TestBundleAdditionalProperties(t *testing.T) {
+	const person = `{
+  "id": "http://example.com/person.json",
+  "definitions": {
+    "age": { "type": "integer", "minimum": 0 }
+  }
+}`
+	const ages = `{
+  "id": "http://example.com/ages.json",
+  "type": "object",
+  "additionalProperties": { "$ref": "person.json#/definitions/age" }
+}`
+
+	r := schema.NewRegistry()
+	var s *schema.Schema
+	for _, src := range []string{person, ages} {
+		var err error
+		s, err = schema.Read(strings.NewReader(src))
+		if !assert.NoError(t, err, "schema.Read should succeed") {
+			return
+		}
+		if !assert.NoError(t, r.Add(s), "Registry.Add should succeed") {
+			return
+		}
+	}
+
+	b, err := s.Bundle()
+	if !assert.NoError(t, err, "Bundle should succeed") {
+		return
+	}
+	if !assert.NotNil(t, b.AdditionalProperties, "additionalProperties should be kept") {
+		return
+	}
+	ref := b.AdditionalProperties.Reference
+	if !assert.True(t, strings.HasPrefix(ref, "#/definitions/"), "reference %s should point into the bundle", ref) {
+		return
+	}
+	age, err := b.AdditionalProperties.Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed within the bundle") {
+		return
+	}
+	if !assert.Equal(t, schema.PrimitiveTypes{schema.IntegerType}, age.Type, "reference should point to the copied definition") {
+		return
+	}
+}
+
+func TestBundleLocal(t *testing.T) {
+	const src = `{
+  "id": "http://example.com/local.json",
+  "definitions": {
+    "name": { "type": "string" }
+  },
+  "properties": {
+    "name": { "$ref": "#/definitions/name" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	b, err := s.Bundle()
+	if !assert.NoError(t, err, "Bundle should succeed") {
+		return
+	}
+	if !assert.True(t, s == b, "Bundle should return the schema itself when it only has local pointers") {
+		return
+	}
+
+	s, err = schema.Read(strings.NewReader(`{ "properties": { "name": { "$ref": "#/definitions/missing" }, "other": { "$ref": "#missing" } } }`))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if _, err := s.Bundle(); !assert.Error(t, err, "Bundle should fail for dangling references") {
+		return
+	}
+}
+
+func TestBundleSubschema(t *testing.T) {
+	const src = `{
+  "definitions": {
+    "name": { "type": "string", "minLength": 1 }
+  },
+  "properties": {
+    "names": {
+      "type": "array",
+      "items": { "$ref": "#/definitions/name" }
+    }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	b, err := s.Properties["names"].Bundle()
+	if !assert.NoError(t, err, "Bundle should succeed") {
+		return
+	}
+	if !assert.Equal(t, schema.PrimitiveTypes{schema.ArrayType}, b.Type, "bundle should be a copy of the subschema") {
+		return
+	}
+
+	item, err := b.Items.Schemas[0].Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed within the bundle") {
+		return
+	}
+	if !assert.Equal(t, 1, item.MinLength.Val, "reference should point to the definition copied from the root") {
+		return
+	}
+}
@@ -0,0 +1,86 @@
+package schema
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// CanonicalJSON serializes the schema into a deterministic JSON
+// form suitable for diffing and caching: object keys are sorted,
+// `type` is always a sorted array, and `required` is sorted.
+// Two schemas that only differ in those respects produce the
+// same output
+func (s *Schema) CanonicalJSON() ([]byte, error) {
+	buf, err := json.Marshal(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal schema")
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal schema")
+	}
+
+	canonicalizeSchema(m)
+	return json.Marshal(m)
+}
+
+func canonicalizeSchema(m map[string]interface{}) {
+	for k, v := range m {
+		switch k {
+		case "type":
+			switch val := v.(type) {
+			case string:
+				m[k] = []interface{}{val}
+			case []interface{}:
+				sortStrings(val)
+			}
+		case "required":
+			if l, ok := v.([]interface{}); ok {
+				sortStrings(l)
+			}
+		case "definitions", "properties", "patternProperties", "dependencies":
+			if sm, ok := v.(map[string]interface{}); ok {
+				for _, sv := range sm {
+					canonicalizeSubschema(sv)
+				}
+			}
+		case "items", "additionalItems", "contains", "additionalProperties", "propertyNames", "allOf", "anyOf", "oneOf", "not", "if", "then", "else":
+			canonicalizeSubschema(v)
+		}
+	}
+}
+
+// canonicalizeSubschema handles a value that is either a schema
+// or a list of schemas. Anything else (e.g. booleans, or the list
+// of names in a property dependency) is left untouched
+func canonicalizeSubschema(v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		canonicalizeSchema(val)
+	case []interface{}:
+		for _, e := range val {
+			if m, ok := e.(map[string]interface{}); ok {
+				canonicalizeSchema(m)
+			}
+		}
+	}
+}
+
+// stringList sorts a list of JSON values by their string value.
+// Elements that are not strings compare as the empty string
+type stringList []interface{}
+
+func (l stringList) Len() int      { return len(l) }
+func (l stringList) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+func (l stringList) Less(i, j int) bool {
+	a, _ := l[i].(string)
+	b, _ := l[j].(string)
+	return a < b
+}
+
+func sortStrings(l []interface{}) {
+	sort.Stable(stringList(l))
+}
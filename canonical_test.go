@@ -0,0 +1,44 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lestrrat/go-jsschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	pairs := [][2]string{
+		{
+			`{"type":"string"}`,
+			`{"type":["string"]}`,
+		},
+		{
+			`{"type":["string","null"],"required":["b","a"]}`,
+			`{"required":["a","b"],"type":["null","string"]}`,
+		},
+		{
+			`{"properties":{"a":{"type":["integer","string"]}},"allOf":[{"type":"object","required":["z","y"]}]}`,
+			`{"allOf":[{"required":["y","z"],"type":["object"]}],"properties":{"a":{"type":["string","integer"]}}}`,
+		},
+	}
+
+	for _, pair := range pairs {
+		var out [2]string
+		for i, src := range pair {
+			s, err := schema.Read(strings.NewReader(src))
+			if !assert.NoError(t, err, "schema.Read should succeed") {
+				return
+			}
+			buf, err := s.CanonicalJSON()
+			if !assert.NoError(t, err, "CanonicalJSON should succeed") {
+				return
+			}
+			out[i] = string(buf)
+		}
+		if !assert.Equal(t, out[0], out[1], "CanonicalJSON should produce identical output for %s and %s", pair[0], pair[1]) {
+			return
+		}
+	}
+}
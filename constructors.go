@@ -0,0 +1,100 @@
+package schema
+
+// newEmpty creates a schema that is equivalent to the empty
+// schema `{}`, i.e. one that allows additional items and
+// additional properties
+func newEmpty() *Schema {
+	s := New()
+	s.AdditionalItems = &AdditionalItems{}
+	s.AdditionalProperties = &AdditionalProperties{}
+	return s
+}
+
+// AllOf creates a new schema that requires values to be valid
+// against all of the given schemas
+func AllOf(schemas ...*Schema) *Schema {
+	s := newEmpty()
+	s.AllOf = SchemaList(schemas)
+	s.applyParentSchema()
+	return s
+}
+
+// AnyOf creates a new schema that requires values to be valid
+// against at least one of the given schemas
+func AnyOf(schemas ...*Schema) *Schema {
+	s := newEmpty()
+	s.AnyOf = SchemaList(schemas)
+	s.applyParentSchema()
+	return s
+}
+
+// OneOf creates a new schema that requires values to be valid
+// against exactly one of the given schemas
+func OneOf(schemas ...*Schema) *Schema {
+	s := newEmpty()
+	s.OneOf = SchemaList(schemas)
+	s.applyParentSchema()
+	return s
+}
+
+// Not creates a new schema that requires values to NOT be valid
+// against the given schema
+func Not(v *Schema) *Schema {
+	s := newEmpty()
+	s.Not = v
+	s.applyParentSchema()
+	return s
+}
+
+func newTyped(t PrimitiveType) *Schema {
+	s := newEmpty()
+	s.Type = PrimitiveTypes{t}
+	return s
+}
+
+// NewString creates a new schema that accepts strings
+func NewString() *Schema {
+	return newTyped(StringType)
+}
+
+// NewInteger creates a new schema that accepts integers
+func NewInteger() *Schema {
+	return newTyped(IntegerType)
+}
+
+// NewNumber creates a new schema that accepts numbers
+func NewNumber() *Schema {
+	return newTyped(NumberType)
+}
+
+// NewBoolean creates a new schema that accepts booleans
+func NewBoolean() *Schema {
+	return newTyped(BooleanType)
+}
+
+// NewNull creates a new schema that accepts null
+func NewNull() *Schema {
+	return newTyped(NullType)
+}
+
+// NewObject creates a new schema that accepts objects with the
+// given properties, of which `required` are mandatory
+func NewObject(props map[string]*Schema, required ...string) *Schema {
+	s := newTyped(ObjectType)
+	s.Properties = props
+	s.Required = required
+	s.applyParentSchema()
+	return s
+}
+
+// NewArray creates a new schema that accepts arrays whose elements
+// are all valid against `items`. If `items` is nil, any element
+// is accepted
+func NewArray(items *Schema) *Schema {
+	s := newTyped(ArrayType)
+	if items != nil {
+		s.Items = &ItemSpec{Schemas: SchemaList{items}}
+		s.applyParentSchema()
+	}
+	return s
+}
@@ -0,0 +1,136 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat/go-jsschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCombinators(t *testing.T) {
+	read := func(src string) *schema.Schema {
+		s, err := schema.Read(strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("schema.Read should succeed: %s", err)
+		}
+		return s
+	}
+
+	tests := []struct {
+		Name     string
+		Schema   *schema.Schema
+		Expected string
+	}{
+		{
+			Name:     "AllOf",
+			Schema:   schema.AllOf(read(`{"type":"string"}`), read(`{"maxLength":3}`)),
+			Expected: `{"allOf":[{"type":"string"},{"maxLength":3}]}`,
+		},
+		{
+			Name:     "AnyOf",
+			Schema:   schema.AnyOf(read(`{"type":"string"}`), read(`{"type":"null"}`)),
+			Expected: `{"anyOf":[{"type":"string"},{"type":"null"}]}`,
+		},
+		{
+			Name:     "OneOf",
+			Schema:   schema.OneOf(read(`{"type":"integer"}`), read(`{"type":"string"}`)),
+			Expected: `{"oneOf":[{"type":"integer"},{"type":"string"}]}`,
+		},
+		{
+			Name:     "Not",
+			Schema:   schema.Not(read(`{"type":"null"}`)),
+			Expected: `{"not":{"type":"null"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Testing %s", test.Name)
+		buf, err := json.Marshal(test.Schema)
+		if !assert.NoError(t, err, "json.Marshal should succeed") {
+			return
+		}
+		if !assert.Equal(t, test.Expected, string(buf), "json.Marshal should produce the expected JSON") {
+			return
+		}
+	}
+
+	inner := read(`{"type":"string"}`)
+	outer := schema.AllOf(inner)
+	if !assert.Equal(t, outer, inner.Root(), "subschemas should have their parent set") {
+		return
+	}
+}
+
+func TestPrimitiveConstructors(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Schema   *schema.Schema
+		Expected string
+	}{
+		{
+			Name:     "String",
+			Schema:   schema.NewString(),
+			Expected: `{"type":"string"}`,
+		},
+		{
+			Name:     "Integer",
+			Schema:   schema.NewInteger(),
+			Expected: `{"type":"integer"}`,
+		},
+		{
+			Name:     "Number",
+			Schema:   schema.NewNumber(),
+			Expected: `{"type":"number"}`,
+		},
+		{
+			Name:     "Boolean",
+			Schema:   schema.NewBoolean(),
+			Expected: `{"type":"boolean"}`,
+		},
+		{
+			Name:     "Null",
+			Schema:   schema.NewNull(),
+			Expected: `{"type":"null"}`,
+		},
+		{
+			Name:     "Array",
+			Schema:   schema.NewArray(schema.NewInteger()),
+			Expected: `{"items":{"type":"integer"},"type":"array"}`,
+		},
+		{
+			Name: "Object",
+			Schema: schema.NewObject(map[string]*schema.Schema{
+				"name": schema.NewString(),
+				"tags": schema.NewArray(schema.NewString()),
+			}, "name"),
+			Expected: `{"properties":{"name":{"type":"string"},"tags":{"items":{"type":"string"},"type":"array"}},"required":["name"],"type":"object"}`,
+		},
+		{
+			Name:     "Composed",
+			Schema:   schema.AnyOf(schema.NewString(), schema.NewNull()),
+			Expected: `{"anyOf":[{"type":"string"},{"type":"null"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Testing %s", test.Name)
+		buf, err := json.Marshal(test.Schema)
+		if !assert.NoError(t, err, "json.Marshal should succeed") {
+			return
+		}
+		if !assert.Equal(t, test.Expected, string(buf), "json.Marshal should produce the expected JSON") {
+			return
+		}
+	}
+
+	name := schema.NewString()
+	obj := schema.NewObject(map[string]*schema.Schema{"name": name})
+	if !assert.Equal(t, obj, name.Root(), "properties should have their parent set") {
+		return
+	}
+	if !assert.False(t, obj.IsPropRequired("name"), "'name' should not be required") {
+		return
+	}
+}
@@ -0,0 +1,72 @@
+package schema
+
+import "net/url"
+
+var draftURIs = map[string]Draft{
+	"json-schema.org/draft-03/schema":       Draft03,
+	"json-schema.org/draft-03/hyper-schema": Draft03,
+	"json-schema.org/draft-04/schema":       Draft04,
+	"json-schema.org/draft-04/hyper-schema": Draft04,
+	"json-schema.org/draft-06/schema":       Draft06,
+	"json-schema.org/draft-06/hyper-schema": Draft06,
+	"json-schema.org/draft-07/schema":       Draft07,
+	"json-schema.org/draft-07/hyper-schema": Draft07,
+	"json-schema.org/draft/2019-09/schema":  Draft201909,
+	"json-schema.org/draft/2020-12/schema":  Draft202012,
+}
+
+// String returns the name of this draft, such as "draft-04"
+func (d Draft) String() string {
+	switch d {
+	case Draft03:
+		return "draft-03"
+	case Draft04:
+		return "draft-04"
+	case Draft06:
+		return "draft-06"
+	case Draft07:
+		return "draft-07"
+	case Draft201909:
+		return "2019-09"
+	case Draft202012:
+		return "2020-12"
+	default:
+		return "<unknown>"
+	}
+}
+
+func draftFromURI(uri string) Draft {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return DraftUnknown
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return DraftUnknown
+	}
+
+	d, ok := draftURIs[u.Host+u.Path]
+	if !ok {
+		return DraftUnknown
+	}
+	return d
+}
+
+// SchemaURI returns the value of the "$schema" keyword of this
+// schema, or the empty string if it was not specified
+func (s *Schema) SchemaURI() string {
+	return s.SchemaRef
+}
+
+// Draft returns the JSON Schema draft declared by the "$schema"
+// keyword of this schema or, if it does not have one, of the
+// closest parent schema that does. Schemas that do not declare a
+// draft are assumed to be Draft04. DraftUnknown is returned if
+// "$schema" does not point to a known meta-schema.
+func (s *Schema) Draft() Draft {
+	for v := s; v != nil; v = v.parent {
+		if v.SchemaRef != "" {
+			return draftFromURI(v.SchemaRef)
+		}
+	}
+	return Draft04
+}
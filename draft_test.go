@@ -0,0 +1,43 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lestrrat/go-jsschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDraft(t *testing.T) {
+	tests := map[string]schema.Draft{
+		"": schema.Draft04,
+		"http://json-schema.org/draft-04/schema#":      schema.Draft04,
+		"http://json-schema.org/draft-04/schema":       schema.Draft04,
+		"https://json-schema.org/draft-04/schema#":     schema.Draft04,
+		"http://json-schema.org/draft-03/schema#":      schema.Draft03,
+		"http://json-schema.org/draft-06/schema#":      schema.Draft06,
+		"http://json-schema.org/draft-07/schema#":      schema.Draft07,
+		"https://json-schema.org/draft/2020-12/schema": schema.Draft202012,
+		"http://example.com/my-meta-schema#":           schema.DraftUnknown,
+	}
+
+	for uri, draft := range tests {
+		src := `{"properties":{"foo":{"type":"string"}}}`
+		if uri != "" {
+			src = `{"$schema":"` + uri + `","properties":{"foo":{"type":"string"}}}`
+		}
+		s, err := schema.Read(strings.NewReader(src))
+		if !assert.NoError(t, err, "schema.Read should succeed") {
+			return
+		}
+		if !assert.Equal(t, uri, s.SchemaURI(), "SchemaURI should return $schema") {
+			return
+		}
+		if !assert.Equal(t, draft, s.Draft(), "Draft should detect %s for '%s'", draft, uri) {
+			return
+		}
+		if !assert.Equal(t, draft, s.Properties["foo"].Draft(), "subschemas should inherit the draft of their parent") {
+			return
+		}
+	}
+}
@@ -3,5 +3,9 @@ package schema
 import "github.com/pkg/errors"
 
 func errInvalidType(s string, v interface{}) error {
-	return errors.Errorf("invalid type: expected %s, got %T", s, v)
+	return errors.Wrapf(ErrInvalidFieldValue, "invalid type: expected %s, got %T", s, v)
+}
+
+func errInvalidValue(format string, args ...interface{}) error {
+	return errors.Wrapf(ErrInvalidFieldValue, "invalid value: "+format, args...)
 }
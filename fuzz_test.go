@@ -0,0 +1,43 @@
+//go:build go1.18
+// +build go1.18
+
+package schema_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/lestrrat/go-jsschema"
+)
+
+func FuzzUnmarshalJSON(f *testing.F) {
+	files, _ := filepath.Glob(filepath.Join("test", "*.json"))
+	for _, file := range files {
+		buf, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatalf("failed to read %s: %s", file, err)
+		}
+		f.Add(buf)
+	}
+	f.Add([]byte(`{"allOf":["x"]}`))
+	f.Add([]byte(`{"items":[true]}`))
+	f.Add([]byte(`{"definitions":{"foo":false}}`))
+	f.Add([]byte(`{"additionalProperties":[]}`))
+	f.Add([]byte(`{"dependencies":{"foo":1}}`))
+	f.Add([]byte(`{"type":["string",1]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		s := schema.New()
+		if err := json.Unmarshal(data, s); err != nil {
+			return
+		}
+
+		// Anything we managed to parse should be serializable
+		if _, err := json.Marshal(s); err != nil {
+			t.Fatalf("json.Marshal failed for %q: %s", data, err)
+		}
+		s.CheckReferences()
+	})
+}
@@ -24,6 +24,12 @@ var ErrExpectedArrayOfString = errors.New("invalid value: expected array of stri
 // This is here only for backwards compatibility
 var ErrInvalidStringArray = ErrExpectedArrayOfString
 
+// ErrInvalidFieldValue is the cause of errors returned when a
+// field in a schema document is of an unexpected type, for
+// example an array where an object is expected, or has a value
+// that is not allowed, such as a negative multipleOf
+var ErrInvalidFieldValue = errors.New("invalid field value")
+
 // PrimitiveType represents a JSON Schema primitive type such as
 // "string", "integer", etc.
 type PrimitiveType int
@@ -66,6 +72,13 @@ type Bool struct {
 	Initialized bool
 }
 
+// Constant represents a "const" value in a JSON Schema. Initialized
+// is needed to distinguish `"const": null` from an absent "const"
+type Constant struct {
+	Val         interface{}
+	Initialized bool
+}
+
 // The list of primitive types
 const (
 	UnspecifiedType PrimitiveType = iota
@@ -78,15 +91,35 @@ const (
 	NumberType
 )
 
+// Draft represents a version of the JSON Schema specification,
+// as declared by the "$schema" keyword
+type Draft int
+
+// The list of JSON Schema drafts that can be detected
+const (
+	DraftUnknown Draft = iota
+	Draft03
+	Draft04
+	Draft06
+	Draft07
+	Draft201909
+	Draft202012
+)
+
 // SchemaList is a list of Schemas
 type SchemaList []*Schema
 
 // Schema represents a JSON Schema object
 type Schema struct {
 	parent          *Schema
+	base            string
 	resolveLock     sync.Mutex
 	resolvedSchemas map[string]interface{}
 	resolver        *jsref.Resolver
+	schemaByID      map[string]*Schema
+	registry        *Registry
+	refResolver     ReferenceResolver
+	hasDefault      bool
 	ID              string             `json:"id,omitempty"`
 	Title           string             `json:"title,omitempty"`
 	Description     string             `json:"description,omitempty"`
@@ -95,8 +128,19 @@ type Schema struct {
 	SchemaRef       string             `json:"$schema,omitempty"`
 	Definitions     map[string]*Schema `json:"definitions,omitempty"`
 	Reference       string             `json:"$ref,omitempty"`
+	Anchor          string             `json:"$anchor,omitempty"`
 	Format          Format             `json:"format,omitempty"`
 
+	// RecursiveReference, RecursiveAnchor, DynamicReference and
+	// DynamicAnchor are parsed so that they can be inspected and
+	// written back, but they are not resolved by Resolve, and
+	// validator.Compile rejects schemas that use them: dynamic scopes
+	// must be tracked during validation, which go-jsval does not do
+	RecursiveReference string `json:"$recursiveRef,omitempty"`
+	RecursiveAnchor    Bool   `json:"$recursiveAnchor,omitempty"`
+	DynamicReference   string `json:"$dynamicRef,omitempty"`
+	DynamicAnchor      string `json:"$dynamicAnchor,omitempty"`
+
 	// NumericValidations
 	MultipleOf       Number `json:"multipleOf,omitempty"`
 	Minimum          Number `json:"minimum,omitempty"`
@@ -115,6 +159,7 @@ type Schema struct {
 	MinItems        Integer
 	MaxItems        Integer
 	UniqueItems     Bool
+	Contains        *Schema // Not enforced by go-jsval, so validator.Compile rejects it
 
 	// ObjectValidations
 	MaxProperties        Integer                    `json:"maxProperties,omitempty"`
@@ -124,12 +169,17 @@ type Schema struct {
 	Properties           map[string]*Schema         `json:"properties,omitempty"`
 	AdditionalProperties *AdditionalProperties      `json:"additionalProperties,omitempty"`
 	PatternProperties    map[*regexp.Regexp]*Schema `json:"patternProperties,omitempty"`
+	PropertyNames        *Schema                    `json:"propertyNames,omitempty"` // Not enforced by go-jsval, so validator.Compile rejects it
 
 	Enum   []interface{}          `json:"enum,omitempty"`
+	Const  Constant               `json:"const,omitempty"` // Not enforced by go-jsval, so validator.Compile rejects it
 	AllOf  SchemaList             `json:"allOf,omitempty"`
 	AnyOf  SchemaList             `json:"anyOf,omitempty"`
 	OneOf  SchemaList             `json:"oneOf,omitempty"`
 	Not    *Schema                `json:"not,omitempty"`
+	If     *Schema                `json:"if,omitempty"` // If, Then and Else are not enforced by go-jsval, so validator.Compile rejects them
+	Then   *Schema                `json:"then,omitempty"`
+	Else   *Schema                `json:"else,omitempty"`
 	Extras map[string]interface{} `json:"-"`
 }
 
@@ -121,6 +121,17 @@ func extractInterface(r *interface{}, m map[string]interface{}, s string) error
 	return nil
 }
 
+func extractConstant(c *Constant, m map[string]interface{}, s string) error {
+	v, ok := m[s]
+	if !ok {
+		return nil
+	}
+
+	c.Val = v
+	c.Initialized = true
+	return nil
+}
+
 func extractInterfaceList(l *[]interface{}, m map[string]interface{}, s string) error {
 	v, ok := m[s]
 	if !ok {
@@ -140,9 +151,9 @@ func extractInterfaceList(l *[]interface{}, m map[string]interface{}, s string)
 	return nil
 }
 
-func extractRegexp(r **regexp.Regexp, m map[string]interface{}, s string) error {
+func extractRegexp(x *extractor, r **regexp.Regexp, m map[string]interface{}, s string) error {
 	v, ok := m[s]
-	if !ok {
+	if !ok || x.noPatterns {
 		return nil
 	}
 	val, ok := v.(string)
@@ -153,7 +164,7 @@ func extractRegexp(r **regexp.Regexp, m map[string]interface{}, s string) error
 		)
 	}
 
-	rx, err := regexp.Compile(val)
+	rx, err := x.compileRegexp(val)
 	if err != nil {
 		return errors.Wrap(
 			errors.Wrapf(
@@ -168,7 +179,7 @@ func extractRegexp(r **regexp.Regexp, m map[string]interface{}, s string) error
 	return nil
 }
 
-func extractSchema(s **Schema, m map[string]interface{}, name string) error {
+func extractSchema(x *extractor, s **Schema, m map[string]interface{}, name string) error {
 	v, ok := m[name]
 	if !ok {
 		return nil
@@ -186,18 +197,18 @@ func extractSchema(s **Schema, m map[string]interface{}, name string) error {
 		)
 	}
 
-	return extractSingleSchema(s, val)
+	return extractSingleSchema(x, s, val)
 }
 
-func extractSingleSchema(s **Schema, m map[string]interface{}) error {
+func extractSingleSchema(x *extractor, s **Schema, m map[string]interface{}) error {
 	*s = New()
-	if err := (*s).Extract(m); err != nil {
+	if err := (*s).extract(x, m); err != nil {
 		return errors.Wrap(err, "failed to extract schema")
 	}
 	return nil
 }
 
-func (l *SchemaList) extractIfPresent(m map[string]interface{}, name string) error {
+func (l *SchemaList) extractIfPresent(x *extractor, m map[string]interface{}, name string) error {
 	v, ok := m[name]
 	if !ok {
 		return nil
@@ -207,13 +218,17 @@ func (l *SchemaList) extractIfPresent(m map[string]interface{}, name string) err
 		pdebug.Printf("Found property '%s'", name)
 	}
 
-	return l.Extract(v)
+	return l.extract(x, v)
 }
 
 // Extract takes either a list of `map[string]interface{}` or
 // a single `map[string]interface{}` to initialize this list
 // of schemas
 func (l *SchemaList) Extract(v interface{}) error {
+	return l.extract(newExtractor(nil), v)
+}
+
+func (l *SchemaList) extract(x *extractor, v interface{}) error {
 	switch val := v.(type) {
 	case []interface{}:
 		*l = make([]*Schema, len(val))
@@ -221,20 +236,20 @@ func (l *SchemaList) Extract(v interface{}) error {
 		for i, d := range val {
 			m, ok := d.(map[string]interface{})
 			if !ok {
-				return errors.Wrap(
+				return errors.Wrapf(
 					errInvalidType("map[string]interface{}", d),
-					"failed to extract schema list",
+					"failed to extract schema list (element #%d)", i,
 				)
 			}
-			if err := extractSingleSchema(&s, m); err != nil {
-				return errors.Wrap(err, "failed to extract schema list")
+			if err := extractSingleSchema(x, &s, m); err != nil {
+				return errors.Wrapf(err, "failed to extract schema list (element #%d)", i)
 			}
 			(*l)[i] = s
 		}
 		return nil
 	case map[string]interface{}:
 		var s *Schema
-		if err := extractSingleSchema(&s, val); err != nil {
+		if err := extractSingleSchema(x, &s, val); err != nil {
 			return errors.Wrap(err, "failed to extract schema list")
 		}
 		*l = []*Schema{s}
@@ -247,15 +262,15 @@ func (l *SchemaList) Extract(v interface{}) error {
 	}
 }
 
-func extractSchemaMapEntry(s *Schema, name string, m map[string]interface{}) error {
+func extractSchemaMapEntry(x *extractor, s *Schema, name string, m map[string]interface{}) error {
 	if pdebug.Enabled {
 		g := pdebug.Marker("Schema map entry '%s'", name)
 		defer g.End()
 	}
-	return s.Extract(m)
+	return s.extract(x, m)
 }
 
-func extractSchemaMap(m map[string]interface{}, name string) (map[string]*Schema, error) {
+func extractSchemaMap(x *extractor, m map[string]interface{}, name string) (map[string]*Schema, error) {
 	v, ok := m[name]
 	if !ok {
 		return nil, nil
@@ -274,15 +289,15 @@ func extractSchemaMap(m map[string]interface{}, name string) (map[string]*Schema
 		// data better be a map
 		m, ok := data.(map[string]interface{})
 		if !ok {
-			return nil, errors.Wrap(
+			return nil, errors.Wrapf(
 				errInvalidType("map[string]interface{}", data),
-				"failed to extract sub field",
+				"failed to extract sub field %s", strconv.Quote(k),
 			)
 		}
 
 		s := New()
-		if err := extractSchemaMapEntry(s, k, m); err != nil {
-			return nil, err
+		if err := extractSchemaMapEntry(x, s, k, m); err != nil {
+			return nil, errors.Wrapf(err, "failed to extract sub field %s", strconv.Quote(k))
 		}
 		r[k] = s
 
@@ -295,9 +310,9 @@ func extractSchemaMap(m map[string]interface{}, name string) (map[string]*Schema
 	return r, nil
 }
 
-func extractRegexpToSchemaMap(m map[string]interface{}, name string) (map[*regexp.Regexp]*Schema, error) {
+func extractRegexpToSchemaMap(x *extractor, m map[string]interface{}, name string) (map[*regexp.Regexp]*Schema, error) {
 	v, ok := m[name]
-	if !ok {
+	if !ok || x.noPatterns {
 		return nil, nil
 	}
 
@@ -314,19 +329,19 @@ func extractRegexpToSchemaMap(m map[string]interface{}, name string) (map[*regex
 		// data better be a map
 		m, ok := data.(map[string]interface{})
 		if !ok {
-			return nil, errors.Wrap(
+			return nil, errors.Wrapf(
 				errInvalidType("map[string]interface{}", data),
-				"failed to extract regexp to schema map",
+				"failed to extract regexp to schema map entry %s", strconv.Quote(k),
 			)
 		}
 		s := New()
-		if err := s.Extract(m); err != nil {
-			return nil, errors.Wrap(err, "failed to extract schema within schema map")
+		if err := s.extract(x, m); err != nil {
+			return nil, errors.Wrapf(err, "failed to extract schema within schema map entry %s", strconv.Quote(k))
 		}
 
-		rx, err := regexp.Compile(k)
+		rx, err := x.compileRegexp(k)
 		if err != nil {
-			return nil, errors.Wrap(err, "failed to compile regular expression for regexp to schema map")
+			return nil, errors.Wrapf(err, "failed to compile regular expression for regexp to schema map entry %s", strconv.Quote(k))
 		}
 
 		r[rx] = s
@@ -334,7 +349,7 @@ func extractRegexpToSchemaMap(m map[string]interface{}, name string) (map[*regex
 	return r, nil
 }
 
-func extractItems(res **ItemSpec, m map[string]interface{}, name string) error {
+func extractItems(x *extractor, res **ItemSpec, m map[string]interface{}, name string) error {
 	v, ok := m[name]
 	if !ok {
 		return nil
@@ -361,14 +376,14 @@ func extractItems(res **ItemSpec, m map[string]interface{}, name string) error {
 
 	var err error
 
-	if err = items.Schemas.extractIfPresent(m, name); err != nil {
+	if err = items.Schemas.extractIfPresent(x, m, name); err != nil {
 		return errors.Wrap(err, "failed to schema for item")
 	}
 	*res = &items
 	return nil
 }
 
-func extractDependecies(res *DependencyMap, m map[string]interface{}, name string) error {
+func extractDependecies(x *extractor, res *DependencyMap, m map[string]interface{}, name string) error {
 	v, ok := m[name]
 	if !ok {
 		return nil
@@ -386,10 +401,10 @@ func extractDependecies(res *DependencyMap, m map[string]interface{}, name strin
 		return nil
 	}
 
-	return res.extract(m)
+	return res.extract(x, m)
 }
 
-func extractType(pt *PrimitiveTypes, m map[string]interface{}, name string) error {
+func extractType(x *extractor, pt *PrimitiveTypes, m map[string]interface{}, name string) error {
 	v, ok := m[name]
 	if !ok {
 		return nil
@@ -397,7 +412,7 @@ func extractType(pt *PrimitiveTypes, m map[string]interface{}, name string) erro
 
 	switch val := v.(type) {
 	case string:
-		t, err := primitiveFromString(val)
+		t, err := x.primitiveType(val)
 		if err != nil {
 			return errors.Wrap(err, "failed to parse primitive type")
 		}
@@ -413,7 +428,7 @@ func extractType(pt *PrimitiveTypes, m map[string]interface{}, name string) erro
 					"failed to extract type",
 				)
 			}
-			t, err := primitiveFromString(s)
+			t, err := x.primitiveType(s)
 			if err != nil {
 				return errors.Wrap(err, "failed to parse primitive type")
 			}
@@ -428,7 +443,7 @@ func extractType(pt *PrimitiveTypes, m map[string]interface{}, name string) erro
 	}
 }
 
-func (dm *DependencyMap) extract(m map[string]interface{}) error {
+func (dm *DependencyMap) extract(x *extractor, m map[string]interface{}) error {
 	dm.Names = make(map[string][]string)
 	dm.Schemas = make(map[string]*Schema)
 	for k, p := range m {
@@ -437,20 +452,20 @@ func (dm *DependencyMap) extract(m map[string]interface{}) error {
 			// This list needs to be a list of strings
 			var l []string
 			if err := convertStringList(&l, val); err != nil {
-				return err
+				return errors.Wrapf(err, "failed to extract dependency %s", strconv.Quote(k))
 			}
 
 			dm.Names[k] = l
 		case map[string]interface{}:
 			s := New()
-			if err := s.Extract(val); err != nil {
-				return err
+			if err := s.extract(x, val); err != nil {
+				return errors.Wrapf(err, "failed to extract dependency %s", strconv.Quote(k))
 			}
 			dm.Schemas[k] = s
 		default:
-			return errors.Wrap(
+			return errors.Wrapf(
 				errInvalidType("[]interface{} or map[string]interface{}", p),
-				"failed to extract 'type'",
+				"failed to extract dependency %s", strconv.Quote(k),
 			)
 		}
 	}
@@ -471,7 +486,15 @@ func (s *Schema) UnmarshalJSON(data []byte) error {
 
 // Extract takes a `map[string]interface{}` and initializes
 // the schema
-func (s *Schema) Extract(m map[string]interface{}) error {
+func (s *Schema) Extract(m map[string]interface{}, options ...ReadOption) error {
+	x := newExtractor(options)
+	if err := s.extract(x, m); err != nil {
+		return err
+	}
+	return x.index(s)
+}
+
+func (s *Schema) extract(x *extractor, m map[string]interface{}) error {
 	if pdebug.Enabled {
 		g := pdebug.IPrintf("START Schema.Extract")
 		defer g.IRelease("END Schema.Extract")
@@ -503,6 +526,30 @@ func (s *Schema) Extract(m map[string]interface{}) error {
 		return errors.Wrap(err, "failed to extract '$ref'")
 	}
 
+	if err = extractString(&s.Anchor, m, "$anchor"); err != nil {
+		return errors.Wrap(err, "failed to extract '$anchor'")
+	}
+
+	if s.ID != "" || s.Anchor != "" {
+		x.identified = append(x.identified, s)
+	}
+
+	if err = extractJSPointer(&s.RecursiveReference, m, "$recursiveRef"); err != nil {
+		return errors.Wrap(err, "failed to extract '$recursiveRef'")
+	}
+
+	if err = extractBool(&s.RecursiveAnchor, m, "$recursiveAnchor", false); err != nil {
+		return errors.Wrap(err, "failed to extract '$recursiveAnchor'")
+	}
+
+	if err = extractJSPointer(&s.DynamicReference, m, "$dynamicRef"); err != nil {
+		return errors.Wrap(err, "failed to extract '$dynamicRef'")
+	}
+
+	if err = extractString(&s.DynamicAnchor, m, "$dynamicAnchor"); err != nil {
+		return errors.Wrap(err, "failed to extract '$dynamicAnchor'")
+	}
+
 	if err = extractFormat(&s.Format, m, "format"); err != nil {
 		return errors.Wrap(err, "failed to extract 'format'")
 	}
@@ -511,39 +558,44 @@ func (s *Schema) Extract(m map[string]interface{}) error {
 		return errors.Wrap(err, "failed to extract 'enum'")
 	}
 
+	if err = extractConstant(&s.Const, m, "const"); err != nil {
+		return errors.Wrap(err, "failed to extract 'const'")
+	}
+
 	if err = extractInterface(&s.Default, m, "default"); err != nil {
 		return errors.Wrap(err, "failed to extract 'default'")
 	}
+	_, s.hasDefault = m["default"]
 
-	if err = extractType(&s.Type, m, "type"); err != nil {
+	if err = extractType(x, &s.Type, m, "type"); err != nil {
 		return errors.Wrap(err, "failed to extract 'type'")
 	}
 
-	if s.Definitions, err = extractSchemaMap(m, "definitions"); err != nil {
+	if s.Definitions, err = extractSchemaMap(x, m, "definitions"); err != nil {
 		return errors.Wrap(err, "failed to extract 'definitions'")
 	}
 
-	if err = extractItems(&s.Items, m, "items"); err != nil {
+	if err = extractItems(x, &s.Items, m, "items"); err != nil {
 		return errors.Wrap(err, "failed to extract 'items'")
 	}
 
-	if err = extractRegexp(&s.Pattern, m, "pattern"); err != nil {
+	if err = extractRegexp(x, &s.Pattern, m, "pattern"); err != nil {
 		return errors.Wrap(err, "failed to extract 'patterns'")
 	}
 
-	if extractInt(&s.MinLength, m, "minLength"); err != nil {
+	if err = extractInt(&s.MinLength, m, "minLength"); err != nil {
 		return errors.Wrap(err, "failed to extract 'minLength'")
 	}
 
-	if extractInt(&s.MaxLength, m, "maxLength"); err != nil {
+	if err = extractInt(&s.MaxLength, m, "maxLength"); err != nil {
 		return errors.Wrap(err, "failed to extract 'maxLength'")
 	}
 
-	if extractInt(&s.MinItems, m, "minItems"); err != nil {
+	if err = extractInt(&s.MinItems, m, "minItems"); err != nil {
 		return errors.Wrap(err, "failed to extract 'minItems'")
 	}
 
-	if extractInt(&s.MaxItems, m, "maxItems"); err != nil {
+	if err = extractInt(&s.MaxItems, m, "maxItems"); err != nil {
 		return errors.Wrap(err, "failed to extract 'maxItems'")
 	}
 
@@ -551,6 +603,10 @@ func (s *Schema) Extract(m map[string]interface{}) error {
 		return errors.Wrap(err, "failed to extract 'uniqueItems'")
 	}
 
+	if err = extractSchema(x, &s.Contains, m, "contains"); err != nil {
+		return errors.Wrap(err, "failed to extract 'contains'")
+	}
+
 	if err = extractInt(&s.MaxProperties, m, "maxProperties"); err != nil {
 		return errors.Wrap(err, "failed to extract 'maxProperties'")
 	}
@@ -578,12 +634,15 @@ func (s *Schema) Extract(m map[string]interface{}) error {
 	if err = extractNumber(&s.MultipleOf, m, "multipleOf"); err != nil {
 		return errors.Wrap(err, "failed to extract 'multipleOf'")
 	}
+	if s.MultipleOf.Initialized && s.MultipleOf.Val <= 0 {
+		return errors.Wrap(errInvalidValue("expected a number greater than 0, got %v", s.MultipleOf.Val), "failed to extract 'multipleOf'")
+	}
 
-	if s.Properties, err = extractSchemaMap(m, "properties"); err != nil {
+	if s.Properties, err = extractSchemaMap(x, m, "properties"); err != nil {
 		return errors.Wrap(err, "failed to extract 'properties'")
 	}
 
-	if err = extractDependecies(&s.Dependencies, m, "dependencies"); err != nil {
+	if err = extractDependecies(x, &s.Dependencies, m, "dependencies"); err != nil {
 		return errors.Wrap(err, "failed to extract 'dependencies'")
 	}
 
@@ -599,7 +658,7 @@ func (s *Schema) Extract(m map[string]interface{}) error {
 		} else {
 			// Oh, it's not a boolean?
 			var apSchema *Schema
-			if err = extractSchema(&apSchema, m, "additionalItems"); err != nil {
+			if err = extractSchema(x, &apSchema, m, "additionalItems"); err != nil {
 				return errors.Wrap(err, "failed to extract 'additionalItems'")
 			}
 			s.AdditionalItems = &AdditionalItems{apSchema}
@@ -618,40 +677,62 @@ func (s *Schema) Extract(m map[string]interface{}) error {
 		} else {
 			// Oh, it's not a boolean?
 			var apSchema *Schema
-			if err = extractSchema(&apSchema, m, "additionalProperties"); err != nil {
+			if err = extractSchema(x, &apSchema, m, "additionalProperties"); err != nil {
 				return errors.Wrap(err, "failed to extract 'additionalProperties'")
 			}
 			s.AdditionalProperties = &AdditionalProperties{apSchema}
 		}
 	}
 
-	if s.PatternProperties, err = extractRegexpToSchemaMap(m, "patternProperties"); err != nil {
+	if s.PatternProperties, err = extractRegexpToSchemaMap(x, m, "patternProperties"); err != nil {
 		return errors.Wrap(err, "failed to extract 'patternProperties'")
 	}
 
-	if err = s.AllOf.extractIfPresent(m, "allOf"); err != nil {
+	if err = extractSchema(x, &s.PropertyNames, m, "propertyNames"); err != nil {
+		return errors.Wrap(err, "failed to extract 'propertyNames'")
+	}
+
+	if err = s.AllOf.extractIfPresent(x, m, "allOf"); err != nil {
 		return errors.Wrap(err, "failed to extract 'allOf'")
 	}
 
-	if err = s.AnyOf.extractIfPresent(m, "anyOf"); err != nil {
+	if err = s.AnyOf.extractIfPresent(x, m, "anyOf"); err != nil {
 		return errors.Wrap(err, "failed to extract 'anyOf'")
 	}
 
-	if err = s.OneOf.extractIfPresent(m, "oneOf"); err != nil {
+	if err = s.OneOf.extractIfPresent(x, m, "oneOf"); err != nil {
 		return errors.Wrap(err, "failed to extract 'oneOf'")
 	}
 
-	if err = extractSchema(&s.Not, m, "not"); err != nil {
+	if err = extractSchema(x, &s.Not, m, "not"); err != nil {
 		return errors.Wrap(err, "failed to extract 'not'")
 	}
 
+	if err = extractSchema(x, &s.If, m, "if"); err != nil {
+		return errors.Wrap(err, "failed to extract 'if'")
+	}
+
+	if err = extractSchema(x, &s.Then, m, "then"); err != nil {
+		return errors.Wrap(err, "failed to extract 'then'")
+	}
+
+	if err = extractSchema(x, &s.Else, m, "else"); err != nil {
+		return errors.Wrap(err, "failed to extract 'else'")
+	}
+
 	s.applyParentSchema()
 
 	s.Extras = make(map[string]interface{})
 	for k, v := range m {
 		switch k {
-		case "id", "title", "description", "required", "$schema", "$ref", "format", "enum", "default", "type", "definitions", "items", "pattern", "minLength", "maxLength", "minItems", "maxItems", "uniqueItems", "maxProperties", "minProperties", "minimum", "exclusiveMinimum", "maximum", "exclusiveMaximum", "multipleOf", "properties", "dependencies", "additionalItems", "additionalProperties", "patternProperties", "allOf", "anyOf", "oneOf", "not":
+		case "id", "title", "description", "required", "$schema", "$ref", "$anchor", "$recursiveRef", "$recursiveAnchor", "$dynamicRef", "$dynamicAnchor", "format", "enum", "const", "default", "type", "definitions", "items", "minLength", "maxLength", "minItems", "maxItems", "uniqueItems", "contains", "maxProperties", "minProperties", "minimum", "exclusiveMinimum", "maximum", "exclusiveMaximum", "multipleOf", "properties", "dependencies", "additionalItems", "additionalProperties", "propertyNames", "allOf", "anyOf", "oneOf", "not", "if", "then", "else":
 			continue
+		case "pattern", "patternProperties":
+			// Kept as is when patterns are disabled, so that
+			// they survive a round trip
+			if !x.noPatterns {
+				continue
+			}
 		}
 		if pdebug.Enabled {
 			pdebug.Printf("Extracting extra field '%s'", k)
@@ -715,6 +796,13 @@ func placeNumber(m map[string]interface{}, name string, n Number) {
 	place(m, name, n.Val)
 }
 
+func placeConstant(m map[string]interface{}, name string, c Constant) {
+	if !c.Initialized {
+		return
+	}
+	place(m, name, c.Val)
+}
+
 func placeInteger(m map[string]interface{}, name string, n Integer) {
 	if !n.Initialized {
 		return
@@ -743,8 +831,16 @@ func (s *Schema) MarshalJSON() ([]byte, error) {
 	placeString(m, "description", s.Description)
 	placeString(m, "$schema", s.SchemaRef)
 	placeString(m, "$ref", s.Reference)
+	placeString(m, "$anchor", s.Anchor)
+	placeString(m, "$recursiveRef", s.RecursiveReference)
+	if s.RecursiveAnchor.Initialized {
+		placeBool(m, "$recursiveAnchor", s.RecursiveAnchor)
+	}
+	placeString(m, "$dynamicRef", s.DynamicReference)
+	placeString(m, "$dynamicAnchor", s.DynamicAnchor)
 	placeStringList(m, "required", s.Required)
 	placeList(m, "enum", s.Enum)
+	placeConstant(m, "const", s.Const)
 	switch len(s.Type) {
 	case 0:
 	case 1:
@@ -780,6 +876,9 @@ func (s *Schema) MarshalJSON() ([]byte, error) {
 	if s.UniqueItems.Initialized {
 		placeBool(m, "uniqueItems", s.UniqueItems)
 	}
+	if v := s.Contains; v != nil {
+		place(m, "contains", v)
+	}
 	placeSchemaMap(m, "definitions", s.Definitions)
 
 	if items := s.Items; items != nil {
@@ -798,12 +897,15 @@ func (s *Schema) MarshalJSON() ([]byte, error) {
 		}
 		placeSchemaMap(m, "patternProperties", rxm)
 	}
+	if v := s.PropertyNames; v != nil {
+		place(m, "propertyNames", v)
+	}
 
 	placeSchemaList(m, "allOf", s.AllOf)
 	placeSchemaList(m, "anyOf", s.AnyOf)
 	placeSchemaList(m, "oneOf", s.OneOf)
 
-	if s.Default != nil {
+	if s.HasDefault() {
 		m["default"] = s.Default
 	}
 
@@ -840,6 +942,18 @@ func (s *Schema) MarshalJSON() ([]byte, error) {
 		place(m, "not", v)
 	}
 
+	if v := s.If; v != nil {
+		place(m, "if", v)
+	}
+
+	if v := s.Then; v != nil {
+		place(m, "then", v)
+	}
+
+	if v := s.Else; v != nil {
+		place(m, "else", v)
+	}
+
 	deps := map[string]interface{}{}
 	if v := s.Dependencies.Schemas; v != nil {
 		for pname, depschema := range v {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/lestrrat/go-jsschema"
 	"github.com/lestrrat/go-jsschema/validator"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -63,3 +64,609 @@ func TestMarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestExtractInvalidSchemaList(t *testing.T) {
+	tests := map[string]string{
+		"allOf": `{"allOf":["x"]}`,
+		"anyOf": `{"anyOf":[{}, true]}`,
+		"oneOf": `{"oneOf":[1]}`,
+		"items": `{"items":["x"]}`,
+	}
+
+	for name, src := range tests {
+		t.Logf("Testing invalid '%s'", name)
+		_, err := schema.Read(strings.NewReader(src))
+		if !assert.Error(t, err, "schema.Read should fail") {
+			return
+		}
+		if !assert.Contains(t, err.Error(), "'"+name+"'", "error should contain the field name") {
+			return
+		}
+		if !assert.Contains(t, err.Error(), "element #", "error should contain the offending element") {
+			return
+		}
+	}
+}
+
+func TestExtractInvalidSchemaMap(t *testing.T) {
+	tests := map[string]string{
+		"definitions":       `{"definitions":{"foo":{},"bar":true}}`,
+		"properties":        `{"properties":{"foo":{},"bar":"baz"}}`,
+		"patternProperties": `{"patternProperties":{"^foo":{},"bar":1}}`,
+	}
+
+	for name, src := range tests {
+		t.Logf("Testing invalid '%s'", name)
+		_, err := schema.Read(strings.NewReader(src))
+		if !assert.Error(t, err, "schema.Read should fail") {
+			return
+		}
+		if !assert.Contains(t, err.Error(), "'"+name+"'", "error should contain the field name") {
+			return
+		}
+		if !assert.Contains(t, err.Error(), `"bar"`, "error should contain the offending key") {
+			return
+		}
+	}
+}
+
+func TestItemsRoundTrip(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Schema    string
+		TupleMode bool
+	}{
+		{
+			Name:      "single schema",
+			Schema:    `{"items":{"type":"string"},"type":"array"}`,
+			TupleMode: false,
+		},
+		{
+			Name:      "tuple with one schema",
+			Schema:    `{"items":[{"type":"string"}],"type":"array"}`,
+			TupleMode: true,
+		},
+		{
+			Name:      "tuple with additionalItems",
+			Schema:    `{"additionalItems":{"type":"integer"},"items":[{"type":"string"},{"type":"number"}],"type":"array"}`,
+			TupleMode: true,
+		},
+		{
+			Name:      "tuple without additionalItems",
+			Schema:    `{"additionalItems":false,"items":[{"type":"string"},{"type":"number"}],"type":"array"}`,
+			TupleMode: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Testing %s", test.Name)
+		s, err := schema.Read(strings.NewReader(test.Schema))
+		if !assert.NoError(t, err, "schema.Read should succeed") {
+			return
+		}
+		if !assert.Equal(t, test.TupleMode, s.Items.TupleMode, "TupleMode should match the JSON form") {
+			return
+		}
+
+		buf, err := json.Marshal(s)
+		if !assert.NoError(t, err, "json.Marshal should succeed") {
+			return
+		}
+		if !assert.Equal(t, test.Schema, string(buf), "json.Marshal should preserve the JSON form") {
+			return
+		}
+	}
+}
+
+func TestExclusiveRangeRoundTrip(t *testing.T) {
+	const src = `{"exclusiveMaximum":true,"exclusiveMinimum":true,"maximum":10,"minimum":1}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if !assert.True(t, s.ExclusiveMinimum.Bool(), "exclusiveMinimum should be true") {
+		return
+	}
+	if !assert.True(t, s.ExclusiveMaximum.Bool(), "exclusiveMaximum should be true") {
+		return
+	}
+
+	buf, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.Equal(t, src, string(buf), "json.Marshal should preserve exclusiveMinimum/exclusiveMaximum") {
+		return
+	}
+}
+
+func TestExtractInvalidInteger(t *testing.T) {
+	for _, name := range []string{"minLength", "maxLength", "minItems", "maxItems", "minProperties", "maxProperties"} {
+		t.Logf("Testing invalid '%s'", name)
+		_, err := schema.Read(strings.NewReader(`{"` + name + `":"foo"}`))
+		if !assert.Error(t, err, "schema.Read should fail") {
+			return
+		}
+		if !assert.Contains(t, err.Error(), "'"+name+"'", "error should contain the field name") {
+			return
+		}
+	}
+}
+
+func TestConst(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Schema   string
+		Expected interface{}
+	}{
+		{
+			Name:     "string",
+			Schema:   `{"const":"foo"}`,
+			Expected: "foo",
+		},
+		{
+			Name:     "number",
+			Schema:   `{"const":42}`,
+			Expected: float64(42),
+		},
+		{
+			Name:     "object",
+			Schema:   `{"const":{"a":[1,"b"]}}`,
+			Expected: map[string]interface{}{"a": []interface{}{float64(1), "b"}},
+		},
+		{
+			Name:     "null",
+			Schema:   `{"const":null}`,
+			Expected: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Testing const with %s value", test.Name)
+		s, err := schema.Read(strings.NewReader(test.Schema))
+		if !assert.NoError(t, err, "schema.Read should succeed") {
+			return
+		}
+		if !assert.True(t, s.Const.Initialized, "const should be initialized") {
+			return
+		}
+		if !assert.Equal(t, test.Expected, s.Const.Val, "const should have the expected value") {
+			return
+		}
+		if _, ok := s.Extras["const"]; !assert.False(t, ok, "const should not be in Extras") {
+			return
+		}
+
+		buf, err := json.Marshal(s)
+		if !assert.NoError(t, err, "json.Marshal should succeed") {
+			return
+		}
+		if !assert.Equal(t, test.Schema, string(buf), "json.Marshal should preserve const") {
+			return
+		}
+	}
+
+	s, err := schema.Read(strings.NewReader(`{}`))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if !assert.False(t, s.Const.Initialized, "const should not be initialized") {
+		return
+	}
+}
+
+func TestContains(t *testing.T) {
+	const src = `{"contains":{"type":"number"},"type":"array"}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if !assert.NotNil(t, s.Contains, "contains should be parsed") {
+		return
+	}
+	if !assert.Equal(t, schema.PrimitiveTypes{schema.NumberType}, s.Contains.Type, "contains should be parsed") {
+		return
+	}
+	if !assert.Equal(t, s, s.Contains.Root(), "contains should have its parent set") {
+		return
+	}
+
+	buf, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.Equal(t, src, string(buf), "json.Marshal should preserve contains") {
+		return
+	}
+}
+
+func TestDependenciesRoundTrip(t *testing.T) {
+	const src = `{"dependencies":{"billing_address":["credit_card"],"credit_card":{"required":["billing_address"]}},"type":"object"}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"credit_card"}, s.Dependencies.Names["billing_address"], "property dependency should be parsed") {
+		return
+	}
+	dep, ok := s.Dependencies.Schemas["credit_card"]
+	if !assert.True(t, ok, "schema dependency should be parsed") {
+		return
+	}
+	if !assert.Equal(t, s, dep.Root(), "schema dependency should have its parent set") {
+		return
+	}
+
+	buf, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.Equal(t, src, string(buf), "json.Marshal should preserve dependencies") {
+		return
+	}
+}
+
+func TestEnumRoundTrip(t *testing.T) {
+	const src = `{"enum":[1,"2"]}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	// Numbers and numeric strings must stay distinct
+	if !assert.Equal(t, []interface{}{float64(1), "2"}, s.Enum, "enum values should keep their types") {
+		return
+	}
+
+	buf, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.Equal(t, src, string(buf), "json.Marshal should preserve enum value types") {
+		return
+	}
+
+	const bools = `{"enum":[true,false]}`
+	s, err = schema.Read(strings.NewReader(bools))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if !assert.Equal(t, []interface{}{true, false}, s.Enum, "boolean enum values should be parsed as bools") {
+		return
+	}
+	buf, err = json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.Equal(t, bools, string(buf), "json.Marshal should preserve boolean enum values") {
+		return
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	// format is an annotation, so unknown values are kept as is
+	const src = `{"format":"checksum","type":"string"}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if !assert.Equal(t, schema.Format("checksum"), s.Format, "unknown format should be parsed") {
+		return
+	}
+
+	buf, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.Equal(t, src, string(buf), "json.Marshal should preserve unknown formats") {
+		return
+	}
+}
+
+func TestRequiredPaths(t *testing.T) {
+	const src = `{"requiredPaths":["address.city","name"],"type":"object"}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	paths, err := s.RequiredPaths()
+	if !assert.NoError(t, err, "RequiredPaths should succeed") {
+		return
+	}
+	if !assert.Equal(t, []string{"address.city", "name"}, paths, "RequiredPaths should return the listed paths") {
+		return
+	}
+
+	buf, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.Equal(t, src, string(buf), "json.Marshal should preserve requiredPaths") {
+		return
+	}
+
+	if paths, err := schema.New().RequiredPaths(); !assert.NoError(t, err, "RequiredPaths should succeed") || !assert.Nil(t, paths, "RequiredPaths should be nil when absent") {
+		return
+	}
+
+	s, err = schema.Read(strings.NewReader(`{"requiredPaths":[1]}`))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if _, err := s.RequiredPaths(); !assert.Error(t, err, "RequiredPaths should fail for non-string paths") {
+		return
+	}
+}
+
+func TestIfThenElse(t *testing.T) {
+	const src = `{"else":{"required":["postal_code"]},"if":{"properties":{"country":{"const":"US"}}},"then":{"required":["zip_code"]}}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	for name, sub := range map[string]*schema.Schema{"if": s.If, "then": s.Then, "else": s.Else} {
+		if !assert.NotNil(t, sub, "'%s' should be parsed", name) {
+			return
+		}
+		if !assert.Equal(t, s, sub.Root(), "'%s' should have its parent set", name) {
+			return
+		}
+		if _, ok := s.Extras[name]; !assert.False(t, ok, "'%s' should not be in Extras", name) {
+			return
+		}
+	}
+	if !assert.Equal(t, []string{"zip_code"}, s.Then.Required, "'then' should be parsed") {
+		return
+	}
+
+	buf, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.Equal(t, src, string(buf), "json.Marshal should preserve if/then/else") {
+		return
+	}
+}
+
+func TestPropertyNames(t *testing.T) {
+	const src = `{"propertyNames":{"maxLength":3},"type":"object"}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if !assert.NotNil(t, s.PropertyNames, "propertyNames should be parsed") {
+		return
+	}
+	if !assert.Equal(t, 3, s.PropertyNames.MaxLength.Val, "propertyNames should be parsed") {
+		return
+	}
+	if !assert.Equal(t, s, s.PropertyNames.Root(), "propertyNames should have its parent set") {
+		return
+	}
+
+	buf, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.Equal(t, src, string(buf), "json.Marshal should preserve propertyNames") {
+		return
+	}
+}
+
+func TestExtractMalformed(t *testing.T) {
+	tests := map[string]string{
+		"array for properties":           `{"properties":[]}`,
+		"string for definitions":         `{"definitions":"foo"}`,
+		"number for patternProperties":   `{"patternProperties":1}`,
+		"array for additionalProperties": `{"additionalProperties":[]}`,
+		"string for additionalItems":     `{"additionalItems":"foo"}`,
+		"number for items":               `{"items":1}`,
+		"array for not":                  `{"not":[]}`,
+		"number for allOf":               `{"allOf":1}`,
+		"array for dependencies":         `{"dependencies":[]}`,
+		"number for dependency":          `{"dependencies":{"foo":1}}`,
+		"malformed dependency schema":    `{"dependencies":{"foo":{"type":1}}}`,
+		"number for type":                `{"type":1}`,
+		"number in type list":            `{"type":["string",1]}`,
+		"string for minLength":           `{"minLength":"1"}`,
+		"number for pattern":             `{"pattern":1}`,
+		"object for enum":                `{"enum":{}}`,
+		"number for title":               `{"title":1}`,
+		"number for contains":            `{"contains":1}`,
+		"string for propertyNames":       `{"propertyNames":"foo"}`,
+		"array for if":                   `{"if":[]}`,
+		"nested malformed schema":        `{"properties":{"foo":{"items":true}}}`,
+		"zero multipleOf":                `{"multipleOf":0}`,
+		"negative multipleOf":            `{"multipleOf":-2}`,
+	}
+
+	for name, src := range tests {
+		t.Logf("Testing %s", name)
+		_, err := schema.Read(strings.NewReader(src))
+		if !assert.Error(t, err, "schema.Read should fail") {
+			return
+		}
+		if !assert.Equal(t, schema.ErrInvalidFieldValue, errors.Cause(err), "error should be caused by ErrInvalidFieldValue") {
+			return
+		}
+	}
+
+	// Not a schema at all
+	for _, src := range []string{`[]`, `"foo"`, `1`} {
+		s := schema.New()
+		if !assert.Error(t, json.Unmarshal([]byte(src), s), "json.Unmarshal should fail for %s", src) {
+			return
+		}
+	}
+
+	_, err := schema.Read(strings.NewReader(`{"required":["foo",1]}`))
+	if !assert.Equal(t, schema.ErrExpectedArrayOfString, errors.Cause(err), "error should be caused by ErrExpectedArrayOfString") {
+		return
+	}
+}
+
+func TestSharedPatterns(t *testing.T) {
+	const src = `{
+  "properties": {
+    "foo": { "pattern": "^[a-z]+$" },
+    "bar": { "pattern": "^[a-z]+$" }
+  },
+  "patternProperties": {
+    "^[a-z]+$": { "type": "string" }
+  }
+}`
+	s1, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	s2, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	rx := s1.Properties["foo"].Pattern
+	if !assert.True(t, rx == s1.Properties["bar"].Pattern, "identical patterns should share a compiled regexp") {
+		return
+	}
+	if !assert.False(t, rx == s2.Properties["foo"].Pattern, "schemas read separately should not share compiled regexps") {
+		return
+	}
+	if !assert.Equal(t, rx.String(), s2.Properties["foo"].Pattern.String(), "schemas read separately should get the same pattern") {
+		return
+	}
+	for k := range s1.PatternProperties {
+		if !assert.True(t, rx == k, "patternProperties should share compiled regexps with pattern") {
+			return
+		}
+	}
+}
+
+func TestLenientTypes(t *testing.T) {
+	const src = `{
+  "type": "Object",
+  "properties": {
+    "name": { "type": "String" },
+    "age": { "type": [ "INTEGER", "null" ] }
+  }
+}`
+
+	_, err := schema.Read(strings.NewReader(src))
+	if !assert.Error(t, err, "schema.Read should reject non-lowercase types by default") {
+		return
+	}
+
+	var warnings []string
+	s, err := schema.Read(strings.NewReader(src), schema.WithLenientTypes(func(err error) {
+		warnings = append(warnings, err.Error())
+	}))
+	if !assert.NoError(t, err, "schema.Read should accept non-lowercase types in lenient mode") {
+		return
+	}
+	if !assert.Equal(t, schema.PrimitiveTypes{schema.ObjectType}, s.Type, "type should be normalized") {
+		return
+	}
+	if !assert.Equal(t, schema.PrimitiveTypes{schema.StringType}, s.Properties["name"].Type, "type should be normalized in subschemas") {
+		return
+	}
+	if !assert.Equal(t, schema.PrimitiveTypes{schema.IntegerType, schema.NullType}, s.Properties["age"].Type, "type lists should be normalized") {
+		return
+	}
+	if !assert.Len(t, warnings, 3, "each normalized type should be reported") {
+		return
+	}
+	if !assert.Contains(t, warnings, `type "String" normalized to "string"`, "warning should name the original type") {
+		return
+	}
+
+	// Still, only known types are accepted
+	_, err = schema.Read(strings.NewReader(`{"type":"Strnig"}`), schema.WithLenientTypes(nil))
+	if !assert.Error(t, err, "schema.Read should reject unknown types in lenient mode") {
+		return
+	}
+}
+
+func TestPatternOptions(t *testing.T) {
+	long := strings.Repeat("a", 65)
+	tests := map[string]string{
+		"pattern":           `{"pattern":"` + long + `"}`,
+		"patternProperties": `{"patternProperties":{"` + long + `":{}}}`,
+		"nested pattern":    `{"properties":{"foo":{"pattern":"` + long + `"}}}`,
+	}
+	for name, src := range tests {
+		t.Logf("Testing over-long %s", name)
+		if _, err := schema.Read(strings.NewReader(src)); !assert.NoError(t, err, "schema.Read should succeed without a limit") {
+			return
+		}
+		_, err := schema.Read(strings.NewReader(src), schema.WithMaxPatternLength(64))
+		if !assert.Error(t, err, "schema.Read should reject patterns over the limit") {
+			return
+		}
+	}
+
+	_, err := schema.Read(strings.NewReader(`{"pattern":"`+long[:64]+`"}`), schema.WithMaxPatternLength(64))
+	if !assert.NoError(t, err, "schema.Read should accept patterns within the limit") {
+		return
+	}
+
+	const src = `{"pattern":"^(a+)+$","patternProperties":{"^x-":{"type":"string"}},"properties":{"foo":{"pattern":"("}}}`
+	s, err := schema.Read(strings.NewReader(src), schema.WithoutPatterns())
+	if !assert.NoError(t, err, "schema.Read should not compile patterns when they are disabled") {
+		return
+	}
+	if !assert.Nil(t, s.Pattern, "pattern should not be compiled") {
+		return
+	}
+	if !assert.Empty(t, s.PatternProperties, "patternProperties should not be compiled") {
+		return
+	}
+	if !assert.Equal(t, "^(a+)+$", s.Extras["pattern"], "pattern should be kept in Extras") {
+		return
+	}
+
+	buf, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.JSONEq(t, src, string(buf), "patterns should survive a round trip") {
+		return
+	}
+}
+
+func TestDynamicReferences(t *testing.T) {
+	const src = `{
+  "$recursiveAnchor": true,
+  "$dynamicAnchor": "node",
+  "type": "object",
+  "properties": {
+    "recursive": { "$recursiveRef": "#" },
+    "dynamic": { "$dynamicRef": "#node" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if !assert.True(t, s.RecursiveAnchor.Bool(), "$recursiveAnchor should be parsed") {
+		return
+	}
+	if !assert.Equal(t, "node", s.DynamicAnchor, "$dynamicAnchor should be parsed") {
+		return
+	}
+	if !assert.Equal(t, "#", s.Properties["recursive"].RecursiveReference, "$recursiveRef should be parsed") {
+		return
+	}
+	if !assert.Equal(t, "#node", s.Properties["dynamic"].DynamicReference, "$dynamicRef should be parsed") {
+		return
+	}
+	if !assert.Empty(t, s.Extras, "parsed keywords should not be kept in Extras") {
+		return
+	}
+
+	buf, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.JSONEq(t, src, string(buf), "dynamic references should survive a round trip") {
+		return
+	}
+}
@@ -0,0 +1,152 @@
+package schema
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ReadOption changes how a schema document is parsed by Read,
+// ReadFile, ReadWithBase, ReadAll and Extract
+type ReadOption func(*extractor)
+
+// extractor holds the options and the state of a single parse, which
+// are shared by a schema and all of its subschemas
+type extractor struct {
+	lenientTypes     bool
+	warn             func(error)
+	maxPatternLength int
+	noPatterns       bool
+
+	// schemas that declare an id or an anchor, to be indexed once
+	// the root schema has been extracted
+	identified []*Schema
+
+	// compiled regular expressions keyed by their source, so that
+	// subschemas of one document sharing a pattern also share the
+	// *regexp.Regexp. Separate documents get their own copies from
+	// patternCache
+	regexps map[string]*regexp.Regexp
+}
+
+func newExtractor(options []ReadOption) *extractor {
+	x := &extractor{}
+	for _, option := range options {
+		option(x)
+	}
+	return x
+}
+
+// index builds the id index of the root schema `s` from the schemas
+// recorded while extracting it, and starts over for the next schema
+func (x *extractor) index(s *Schema) error {
+	list := x.identified
+	x.identified = nil
+	return s.indexIDs(list)
+}
+
+// WithLenientTypes makes the parser accept primitive type names
+// that are not lowercase, such as "String", as some hand written
+// schemas do. Such names are normalized, and reported to `warn` if it
+// is not nil. By default these names are rejected.
+func WithLenientTypes(warn func(error)) ReadOption {
+	return func(x *extractor) {
+		x.lenientTypes = true
+		x.warn = warn
+	}
+}
+
+// WithMaxPatternLength makes the parser reject regular expressions in
+// "pattern" and "patternProperties" that are longer than `n` bytes.
+// Use it, or WithoutPatterns, when parsing untrusted schemas
+func WithMaxPatternLength(n int) ReadOption {
+	return func(x *extractor) {
+		x.maxPatternLength = n
+	}
+}
+
+// WithoutPatterns makes the parser skip compiling "pattern" and
+// "patternProperties". Their values are kept in Extras instead, so
+// they are not enforced by validators, but are written back by
+// MarshalJSON
+func WithoutPatterns() ReadOption {
+	return func(x *extractor) {
+		x.noPatterns = true
+	}
+}
+
+// maxCachedPatterns is the number of compiled regular expressions
+// kept by patternCache. Patterns seen once it is full are compiled
+// every time, so untrusted schemas cannot grow it without bound
+const maxCachedPatterns = 1024
+
+// patternCache holds regular expressions compiled by earlier parses,
+// so that applications reading many schemas with the same patterns
+// only compile each of them once. Schemas are only ever given copies,
+// so calling Longest on one does not affect others
+var patternCache = struct {
+	sync.RWMutex
+	regexps map[string]*regexp.Regexp
+}{
+	regexps: make(map[string]*regexp.Regexp),
+}
+
+func cachedRegexp(src string) (*regexp.Regexp, error) {
+	patternCache.RLock()
+	rx, ok := patternCache.regexps[src]
+	patternCache.RUnlock()
+	if ok {
+		return rx.Copy(), nil
+	}
+
+	rx, err := regexp.Compile(src)
+	if err != nil {
+		return nil, err
+	}
+
+	patternCache.Lock()
+	if len(patternCache.regexps) < maxCachedPatterns {
+		patternCache.regexps[src] = rx
+	}
+	patternCache.Unlock()
+	return rx.Copy(), nil
+}
+
+func (x *extractor) compileRegexp(src string) (*regexp.Regexp, error) {
+	if x.maxPatternLength > 0 && len(src) > x.maxPatternLength {
+		return nil, errors.Errorf("pattern is %d bytes long, exceeding the limit of %d", len(src), x.maxPatternLength)
+	}
+
+	if rx, ok := x.regexps[src]; ok {
+		return rx, nil
+	}
+	rx, err := cachedRegexp(src)
+	if err != nil {
+		return nil, err
+	}
+	if x.regexps == nil {
+		x.regexps = make(map[string]*regexp.Regexp)
+	}
+	x.regexps[src] = rx
+	return rx, nil
+}
+
+func (x *extractor) primitiveType(s string) (PrimitiveType, error) {
+	t, err := primitiveFromString(s)
+	if err == nil || !x.lenientTypes {
+		return t, err
+	}
+
+	lower := strings.ToLower(s)
+	t, lerr := primitiveFromString(lower)
+	if lerr != nil {
+		return t, err
+	}
+	if x.warn != nil {
+		x.warn(errors.Errorf("type %s normalized to %s", strconv.Quote(s), strconv.Quote(lower)))
+	}
+	return t, nil
+}
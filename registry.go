@@ -0,0 +1,122 @@
+package schema
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Registry holds a set of schemas indexed by their `id`, so that
+// schemas can refer to each other by absolute URI. A schema that has
+// been added to a registry consults it when resolving references to
+// other schemas in the same registry.
+type Registry struct {
+	lock    sync.RWMutex
+	schemas map[string]*Schema
+}
+
+// NewRegistry creates a new, empty Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		schemas: make(map[string]*Schema),
+	}
+}
+
+func registryKey(id string) string {
+	return strings.TrimSuffix(id, "#")
+}
+
+// Add registers the schema `s` in the registry under its scope,
+// which is its `id`, resolved against the base URL if it was read
+// with ReadWithBase. The scope must be an absolute URI.
+func (r *Registry) Add(s *Schema) error {
+	id := s.ResolvedScope()
+	u, err := url.Parse(id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse id %s", strconv.Quote(id))
+	}
+	if !u.IsAbs() {
+		return errors.Errorf("schema id %s is not an absolute URI", strconv.Quote(id))
+	}
+
+	r.lock.Lock()
+	r.schemas[registryKey(id)] = s
+	r.lock.Unlock()
+
+	s.registry = r
+	return nil
+}
+
+func (r *Registry) lookup(id string) (*Schema, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	s, ok := r.schemas[registryKey(id)]
+	return s, ok
+}
+
+// Resolve returns the schema pointed to by `uri`. The URI without
+// its fragment must match the id of a registered schema. The fragment,
+// if any, may be either a JSON pointer or an anchor name
+func (r *Registry) Resolve(uri string) (*Schema, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse uri %s", strconv.Quote(uri))
+	}
+
+	fragment := u.Fragment
+	u.Fragment = ""
+	doc, ok := r.lookup(u.String())
+	if !ok {
+		return nil, errors.Errorf("schema %s not found in registry", strconv.Quote(u.String()))
+	}
+
+	return resolveFragment(doc, fragment)
+}
+
+// resolveFragment returns the schema within `doc` that is pointed
+// to by `fragment`, which may be either empty, a JSON pointer, or an
+// anchor name
+func resolveFragment(doc *Schema, fragment string) (*Schema, error) {
+	if fragment == "" {
+		return doc, nil
+	}
+
+	if name, ok := anchorName("#" + fragment); ok {
+		return doc.findSchemaByAnchor(name)
+	}
+
+	thing, err := doc.resolver.Resolve(doc, "#"+fragment)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve fragment %s", strconv.Quote(fragment))
+	}
+	s, ok := thing.(*Schema)
+	if !ok {
+		return nil, errors.Errorf("resolved fragment %s is not a schema", strconv.Quote(fragment))
+	}
+	return s, nil
+}
+
+// registryReference returns the absolute URI for this schema's
+// reference if it points to a schema registered in the registry
+// of the root schema
+func (s *Schema) registryReference() (string, bool) {
+	r := s.Root().registry
+	if r == nil {
+		return "", false
+	}
+
+	u, err := s.ResolveURL(s.Reference)
+	if err != nil || !u.IsAbs() {
+		return "", false
+	}
+
+	doc := *u
+	doc.Fragment = ""
+	if _, ok := r.lookup(doc.String()); !ok {
+		return "", false
+	}
+	return u.String(), true
+}
@@ -0,0 +1,74 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lestrrat/go-jsschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	const person = `{
+  "id": "http://example.com/person.json#",
+  "definitions": {
+    "name": { "type": "string", "minLength": 1 }
+  },
+  "type": "object",
+  "properties": {
+    "name": { "$ref": "#/definitions/name" }
+  }
+}`
+	const team = `{
+  "id": "http://example.com/team.json",
+  "type": "object",
+  "properties": {
+    "name": { "$ref": "person.json#/definitions/name" },
+    "leader": { "$ref": "http://example.com/person.json" }
+  }
+}`
+
+	r := schema.NewRegistry()
+	var schemas []*schema.Schema
+	for _, src := range []string{person, team} {
+		s, err := schema.Read(strings.NewReader(src))
+		if !assert.NoError(t, err, "schema.Read should succeed") {
+			return
+		}
+		if !assert.NoError(t, r.Add(s), "Registry.Add should succeed") {
+			return
+		}
+		schemas = append(schemas, s)
+	}
+
+	resolved, err := r.Resolve("http://example.com/team.json")
+	if !assert.NoError(t, err, "Registry.Resolve should succeed") {
+		return
+	}
+	if !assert.Equal(t, schemas[1], resolved, "Registry.Resolve should return the team schema") {
+		return
+	}
+
+	resolved, err = schemas[1].Properties["leader"].Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed") {
+		return
+	}
+	if !assert.Equal(t, schemas[0], resolved, "Resolve should return the person schema") {
+		return
+	}
+
+	resolved, err = schemas[1].Properties["name"].Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed") {
+		return
+	}
+	if !assert.Equal(t, schemas[0].Definitions["name"], resolved, "Resolve should return the name definition in the person schema") {
+		return
+	}
+
+	if !assert.Error(t, r.Add(schema.New()), "Registry.Add should fail for schemas without an absolute id") {
+		return
+	}
+	if _, err := r.Resolve("http://example.com/unknown.json"); !assert.Error(t, err, "Registry.Resolve should fail for unknown schemas") {
+		return
+	}
+}
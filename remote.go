@@ -0,0 +1,169 @@
+package schema
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ReferenceResolver fetches schema documents that are referred to
+// by absolute URLs which cannot be resolved locally. The URL passed
+// to Resolve never contains a fragment.
+type ReferenceResolver interface {
+	Resolve(u *url.URL) (*Schema, error)
+}
+
+// DefaultMaxDocumentSize is the size limit, in bytes, of documents
+// fetched by an HTTPResolver whose MaxSize is not set
+const DefaultMaxDocumentSize = 4 << 20
+
+// defaultHTTPClient is used by HTTPResolvers without a Client, so
+// that a stalled server cannot block resolution forever
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// HTTPResolver is a ReferenceResolver that fetches schema documents
+// over HTTP(S). Documents are cached by URL, so each one is only
+// fetched once, even by concurrent callers. Failures are cached too.
+type HTTPResolver struct {
+	// Client is the client used to fetch documents. If nil, a client
+	// with a 30 second timeout is used
+	Client *http.Client
+
+	// MaxSize is the size limit of a document, in bytes. If zero,
+	// DefaultMaxDocumentSize is used
+	MaxSize int64
+
+	lock  sync.Mutex
+	cache map[string]*fetchCall
+}
+
+// fetchCall is a fetch that is in progress or done. `done` is closed
+// once `schema` and `err` are set
+type fetchCall struct {
+	done   chan struct{}
+	schema *Schema
+	err    error
+}
+
+// NewHTTPResolver creates a new HTTPResolver
+func NewHTTPResolver() *HTTPResolver {
+	return &HTTPResolver{
+		cache: make(map[string]*fetchCall),
+	}
+}
+
+// Resolve fetches the schema document at `u`
+func (r *HTTPResolver) Resolve(u *url.URL) (*Schema, error) {
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		return nil, errors.Errorf("unsupported scheme %s", strconv.Quote(u.Scheme))
+	}
+
+	key := u.String()
+	r.lock.Lock()
+	if r.cache == nil {
+		r.cache = make(map[string]*fetchCall)
+	}
+	c, ok := r.cache[key]
+	if !ok {
+		c = &fetchCall{done: make(chan struct{})}
+		r.cache[key] = c
+	}
+	r.lock.Unlock()
+
+	if ok {
+		<-c.done
+		return c.schema, c.err
+	}
+
+	c.schema, c.err = r.fetch(key)
+	close(c.done)
+	return c.schema, c.err
+}
+
+func (r *HTTPResolver) fetch(key string) (*Schema, error) {
+	client := r.Client
+	if client == nil {
+		client = defaultHTTPClient
+	}
+	max := r.MaxSize
+	if max <= 0 {
+		max = DefaultMaxDocumentSize
+	}
+
+	res, err := client.Get(key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch %s", strconv.Quote(key))
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to fetch %s: %s", strconv.Quote(key), res.Status)
+	}
+
+	buf, err := ioutil.ReadAll(io.LimitReader(res.Body, max+1))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch %s", strconv.Quote(key))
+	}
+	if int64(len(buf)) > max {
+		return nil, errors.Errorf("failed to fetch %s: document exceeds %d bytes", strconv.Quote(key), max)
+	}
+
+	s, err := ReadWithBase(bytes.NewReader(buf), key)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse schema from %s", strconv.Quote(key))
+	}
+	s.refResolver = r
+	return s, nil
+}
+
+// SetReferenceResolver sets the ReferenceResolver used to fetch
+// documents for references to absolute URLs outside of this schema.
+// It is consulted by all subschemas of this schema. By default no
+// ReferenceResolver is set, and such references fail to resolve
+func (s *Schema) SetReferenceResolver(r ReferenceResolver) {
+	s.Root().refResolver = r
+}
+
+func isBuiltinSchemaURL(u *url.URL) bool {
+	v := strings.TrimSuffix(u.String(), "#")
+	return v == SchemaURL || v == HyperSchemaURL
+}
+
+// remoteReference returns the absolute URL of the document that
+// this schema's reference points to, along with the fragment, if
+// it should be fetched through the root schema's ReferenceResolver
+func (s *Schema) remoteReference() (*url.URL, string, bool) {
+	root := s.Root()
+	if root.refResolver == nil {
+		return nil, "", false
+	}
+
+	u, err := s.ResolveURL(s.Reference)
+	if err != nil || !u.IsAbs() {
+		return nil, "", false
+	}
+
+	doc := *u
+	doc.Fragment = ""
+	if isBuiltinSchemaURL(&doc) {
+		return nil, "", false
+	}
+
+	self := *root.BaseURL()
+	self.Fragment = ""
+	if doc.String() == self.String() {
+		return nil, "", false
+	}
+
+	return &doc, u.Fragment, true
+}
@@ -0,0 +1,192 @@
+package schema_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lestrrat/go-jsschema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPResolver(t *testing.T) {
+	const defs = `{
+  "definitions": {
+    "name": { "type": "string" },
+    "age": { "type": "integer" }
+  }
+}`
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/defs.json" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&count, 1)
+		w.Header().Set("Content-Type", schema.MIMEType)
+		w.Write([]byte(defs))
+	}))
+	defer srv.Close()
+
+	src := `{
+  "properties": {
+    "name": { "$ref": "` + srv.URL + `/defs.json#/definitions/name" },
+    "age": { "$ref": "` + srv.URL + `/defs.json#/definitions/age" },
+    "missing": { "$ref": "` + srv.URL + `/missing.json" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	_, err = s.Properties["name"].Resolve(nil)
+	if !assert.Error(t, err, "Resolve should fail without a ReferenceResolver") {
+		return
+	}
+	if !assert.Equal(t, int32(0), atomic.LoadInt32(&count), "nothing should be fetched without a ReferenceResolver") {
+		return
+	}
+
+	// Start over, as failures are cached
+	s, err = schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	s.SetReferenceResolver(schema.NewHTTPResolver())
+
+	for _, name := range []string{"name", "age"} {
+		resolved, err := s.Properties[name].Resolve(nil)
+		if !assert.NoError(t, err, "Resolve should succeed for '%s'", name) {
+			return
+		}
+		if !assert.Len(t, resolved.Type, 1, "resolved schema for '%s' should have a type", name) {
+			return
+		}
+	}
+	if !assert.Equal(t, int32(1), atomic.LoadInt32(&count), "remote document should be fetched once") {
+		return
+	}
+
+	_, err = s.Properties["missing"].Resolve(nil)
+	if !assert.Error(t, err, "Resolve should fail for a missing document") {
+		return
+	}
+}
+
+func TestCheckReferencesRemote(t *testing.T) {
+	var count int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	src := `{
+  "properties": {
+    "remote": { "$ref": "` + srv.URL + `/defs.json#/definitions/name" },
+    "local": { "$ref": "#/definitions/missing" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	s.SetReferenceResolver(schema.NewHTTPResolver())
+
+	errs := s.CheckReferences()
+	if !assert.Len(t, errs, 1, "CheckReferences should only report the local reference") {
+		return
+	}
+	if !assert.Contains(t, errs[0].Error(), "#/properties/local", "error should contain the location of the reference") {
+		return
+	}
+	if !assert.Equal(t, int32(0), atomic.LoadInt32(&count), "CheckReferences should not fetch remote documents") {
+		return
+	}
+}
+
+func TestHTTPResolverCache(t *testing.T) {
+	const defs = `{
+  "definitions": {
+    "name": { "type": "string" }
+  }
+}`
+	var count, missing int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/defs.json":
+			atomic.AddInt32(&count, 1)
+			w.Write([]byte(defs))
+		default:
+			atomic.AddInt32(&missing, 1)
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	r := schema.NewHTTPResolver()
+	u, err := url.Parse(srv.URL + "/defs.json")
+	if !assert.NoError(t, err, "url.Parse should succeed") {
+		return
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = r.Resolve(u)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if !assert.NoError(t, err, "Resolve should succeed") {
+			return
+		}
+	}
+	if !assert.Equal(t, int32(1), atomic.LoadInt32(&count), "document should be fetched once by concurrent callers") {
+		return
+	}
+
+	u, err = url.Parse(srv.URL + "/missing.json")
+	if !assert.NoError(t, err, "url.Parse should succeed") {
+		return
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := r.Resolve(u); !assert.Error(t, err, "Resolve should fail for a missing document") {
+			return
+		}
+	}
+	if !assert.Equal(t, int32(1), atomic.LoadInt32(&missing), "failures should be cached") {
+		return
+	}
+}
+
+func TestHTTPResolverMaxSize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{ "description": "` + strings.Repeat("x", 64) + `" }`))
+	}))
+	defer srv.Close()
+
+	u, err := url.Parse(srv.URL + "/large.json")
+	if !assert.NoError(t, err, "url.Parse should succeed") {
+		return
+	}
+
+	r := schema.NewHTTPResolver()
+	r.MaxSize = 32
+	if _, err := r.Resolve(u); !assert.Error(t, err, "Resolve should fail for a document over the limit") {
+		return
+	}
+
+	r = schema.NewHTTPResolver()
+	if _, err := r.Resolve(u); !assert.NoError(t, err, "Resolve should succeed within the default limit") {
+		return
+	}
+}
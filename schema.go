@@ -1,12 +1,16 @@
 package schema
 
 import (
+	"bufio"
 	"encoding/json"
 	"io"
 	"net/url"
 	"os"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"unicode"
 
 	"github.com/lestrrat/go-jsref"
 	"github.com/lestrrat/go-jsref/provider"
@@ -45,34 +49,128 @@ func (s *Schema) initialize() {
 
 // ReadFile reads the file `f` and parses its content to create
 // a new Schema object
-func ReadFile(f string) (*Schema, error) {
+func ReadFile(f string, options ...ReadOption) (*Schema, error) {
 	in, err := os.Open(f)
 	if err != nil {
 		return nil, err
 	}
 	defer in.Close()
-	return Read(in)
+	return Read(in, options...)
 }
 
 // Read reads from `in` and parses its content to create
 // a new Schema object
-func Read(in io.Reader) (*Schema, error) {
+func Read(in io.Reader, options ...ReadOption) (*Schema, error) {
 	s := New()
-	if err := s.Decode(in); err != nil {
+	if err := s.decode(newExtractor(options), in); err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
+// ReadWithBase is like Read, but uses `base` as the URL that the
+// schema was read from. The scope of the schema, and thus relative
+// ids and references within it, are resolved against `base`.
+func ReadWithBase(in io.Reader, base string, options ...ReadOption) (*Schema, error) {
+	if _, err := url.Parse(base); err != nil {
+		return nil, errors.Wrapf(err, "invalid base URL %s", strconv.Quote(base))
+	}
+
+	s := New()
+	s.base = base
+	if err := s.decode(newExtractor(options), in); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ReadAll reads multiple schemas from `in`. The content may be
+// either a JSON array of schemas, or a stream of schemas separated
+// by whitespace, such as newline delimited JSON
+func ReadAll(in io.Reader, options ...ReadOption) ([]*Schema, error) {
+	x := newExtractor(options)
+	rdr := bufio.NewReader(in)
+	for {
+		c, _, err := rdr.ReadRune()
+		if err != nil {
+			if err == io.EOF {
+				return nil, nil
+			}
+			return nil, errors.Wrap(err, "failed to read schemas")
+		}
+		if unicode.IsSpace(c) {
+			continue
+		}
+		if err := rdr.UnreadRune(); err != nil {
+			return nil, errors.Wrap(err, "failed to read schemas")
+		}
+		if c == '[' {
+			return readSchemaArray(x, rdr)
+		}
+		return readSchemaStream(x, rdr)
+	}
+}
+
+func readSchemaArray(x *extractor, in io.Reader) ([]*Schema, error) {
+	var list []map[string]interface{}
+	dec := json.NewDecoder(in)
+	if err := dec.Decode(&list); err != nil {
+		return nil, errors.Wrap(err, "failed to decode list of schemas")
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("failed to decode list of schemas: unexpected data after JSON array")
+	}
+
+	schemas := make([]*Schema, len(list))
+	for i, m := range list {
+		s := New()
+		if err := s.extract(x, m); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode schema #%d", i)
+		}
+		if err := x.index(s); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode schema #%d", i)
+		}
+		schemas[i] = s
+	}
+	return schemas, nil
+}
+
+func readSchemaStream(x *extractor, in io.Reader) ([]*Schema, error) {
+	var schemas []*Schema
+	dec := json.NewDecoder(in)
+	for {
+		s := New()
+		if err := s.decodeNext(x, dec); err != nil {
+			if err == io.EOF {
+				return schemas, nil
+			}
+			return nil, errors.Wrapf(err, "failed to decode schema #%d", len(schemas))
+		}
+		schemas = append(schemas, s)
+	}
+}
+
 // Decode reads from `in` and parses its content to
 // initialize the schema object
 func (s *Schema) Decode(in io.Reader) error {
-	dec := json.NewDecoder(in)
-	if err := dec.Decode(s); err != nil {
+	return s.decode(newExtractor(nil), in)
+}
+
+func (s *Schema) decode(x *extractor, in io.Reader) error {
+	return s.decodeNext(x, json.NewDecoder(in))
+}
+
+// decodeNext initializes the schema from the next JSON value in `dec`
+func (s *Schema) decodeNext(x *extractor, dec *json.Decoder) error {
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return err
+	}
+	if err := s.extract(x, m); err != nil {
 		return err
 	}
 	s.applyParentSchema()
-	return nil
+	return x.index(s)
 }
 
 func (s *Schema) setParent(v *Schema) {
@@ -104,12 +202,31 @@ func (s *Schema) applyParentSchema() {
 			v.applyParentSchema()
 		}
 	}
+	if v := s.Contains; v != nil {
+		v.setParent(s)
+		v.applyParentSchema()
+	}
 
 	for _, v := range s.Properties {
 		v.setParent(s)
 		v.applyParentSchema()
 	}
 
+	for _, v := range s.PatternProperties {
+		v.setParent(s)
+		v.applyParentSchema()
+	}
+
+	if v := s.PropertyNames; v != nil {
+		v.setParent(s)
+		v.applyParentSchema()
+	}
+
+	for _, v := range s.Dependencies.Schemas {
+		v.setParent(s)
+		v.applyParentSchema()
+	}
+
 	for _, v := range s.AllOf {
 		v.setParent(s)
 		v.applyParentSchema()
@@ -129,11 +246,66 @@ func (s *Schema) applyParentSchema() {
 		v.setParent(s)
 		v.applyParentSchema()
 	}
+
+	if v := s.If; v != nil {
+		v.setParent(s)
+		v.applyParentSchema()
+	}
+
+	if v := s.Then; v != nil {
+		v.setParent(s)
+		v.applyParentSchema()
+	}
+
+	if v := s.Else; v != nil {
+		v.setParent(s)
+		v.applyParentSchema()
+	}
+}
+
+// indexIDs records the schemas in `list`, which must all be within
+// this tree and declare an id or an anchor, keyed by the URL that
+// identifies them. It should only be called on the root, once the
+// whole tree has been extracted
+func (s *Schema) indexIDs(list []*Schema) error {
+	index := make(map[string]*Schema, len(list))
+	for _, v := range list {
+		for _, key := range v.indexKeys() {
+			if prev, ok := index[key]; ok && prev != v {
+				return errors.Errorf("duplicate schema identifier %s", strconv.Quote(key))
+			}
+			index[key] = v
+		}
+	}
+	s.schemaByID = index
+	return nil
+}
+
+// indexKeys returns the URLs that identify this schema: the resolved
+// scope if it declares an id, and the location of its anchor if it
+// declares one. Anchors are relative to the enclosing scope
+func (s *Schema) indexKeys() []string {
+	if s.ID == "" && s.Anchor == "" {
+		return nil
+	}
+
+	var keys []string
+	scope := strings.TrimSuffix(s.ResolvedScope(), "#")
+	if s.ID != "" {
+		keys = append(keys, scope)
+	}
+	if s.Anchor != "" {
+		if i := strings.IndexByte(scope, '#'); i >= 0 {
+			scope = scope[:i]
+		}
+		keys = append(keys, scope+"#"+s.Anchor)
+	}
+	return keys
 }
 
 // BaseURL returns the base URL registered for this schema
 func (s *Schema) BaseURL() *url.URL {
-	scope := s.Scope()
+	scope := s.ResolvedScope()
 	u, err := url.Parse(scope)
 	if err != nil {
 		// XXX hmm, not sure what to do here
@@ -158,13 +330,274 @@ func (s *Schema) Root() *Schema {
 	return s.parent.Root()
 }
 
+// findSchemaByID looks for the schema identified by `id`, among
+// this schema and its subschemas that declare an id or an anchor
 func (s *Schema) findSchemaByID(id string) (*Schema, error) {
-	if s.ID == id {
-		return s, nil
+	id = strings.TrimSuffix(id, "#")
+
+	if v, ok := s.schemaByID[id]; ok {
+		return v, nil
+	}
+
+	// Not indexed, either because this schema was assembled by hand,
+	// or because it was modified after it was read
+	var found *Schema
+	s.Walk(func(v *Schema) bool {
+		for _, key := range v.indexKeys() {
+			if key == id {
+				found = v
+				return false
+			}
+		}
+		return true
+	})
+	if found == nil {
+		return nil, errors.Errorf("schema %s not found", strconv.Quote(id))
+	}
+	return found, nil
+}
+
+// ResolveID returns the schema within this schema's document that
+// declares the given id. Relative ids are resolved against the scope
+// of this schema.
+func (s *Schema) ResolveID(id string) (*Schema, error) {
+	u, err := s.ResolveURL(id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve id %s", strconv.Quote(id))
+	}
+	return s.Root().findSchemaByID(u.String())
+}
+
+// idReference returns the schema that declares the id that this
+// schema's reference points to, along with the fragment part of
+// the reference
+func (s *Schema) idReference() (*Schema, string, bool) {
+	u, err := s.ResolveURL(s.Reference)
+	if err != nil {
+		return nil, "", false
+	}
+
+	root := s.Root()
+	if u.Fragment != "" {
+		// draft-04 ids may name a fragment themselves, as in "#foo"
+		if target, err := root.findSchemaByID(u.String()); err == nil {
+			return target, "", true
+		}
+	}
+
+	fragment := u.Fragment
+	doc := *u
+	doc.Fragment = ""
+	if doc.String() == "" {
+		return nil, "", false
+	}
+
+	target, err := root.findSchemaByID(doc.String())
+	if err != nil {
+		return nil, "", false
+	}
+	return target, fragment, true
+}
+
+// anchorName returns the anchor name if `ref` is a plain name
+// fragment such as "#foo", as opposed to a JSON pointer fragment
+func anchorName(ref string) (string, bool) {
+	if len(ref) < 2 || ref[0] != '#' || ref[1] == '/' {
+		return "", false
+	}
+	return ref[1:], true
+}
+
+func resolveAnchor(ctx interface{}, name string) (*Schema, error) {
+	root, ok := ctx.(*Schema)
+	if !ok {
+		return nil, errors.Errorf("cannot resolve anchor %s against %T", strconv.Quote(name), ctx)
+	}
+	return root.findSchemaByAnchor(name)
+}
+
+// findSchemaByAnchor looks for the schema that declares the anchor
+// `name` within the resource identified by this schema. Subschemas
+// that declare an id of their own are separate resources, with their
+// own anchors, so they are not searched
+func (s *Schema) findSchemaByAnchor(name string) (*Schema, error) {
+	found := s.anchorInResource(name)
+	if found == nil {
+		return nil, errors.Errorf("anchor %s not found", strconv.Quote(name))
+	}
+	return found, nil
+}
+
+func (s *Schema) anchorInResource(name string) *Schema {
+	if s.Anchor == name {
+		return s
+	}
+	for _, v := range s.subschemas() {
+		if id := v.schema.ID; id != "" && !strings.HasPrefix(id, "#") {
+			continue
+		}
+		if found := v.schema.anchorInResource(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// Walk calls `fn` on this schema and on every schema nested
+// within it (definitions, properties, items, allOf, etc), depth first.
+// References are not followed. Traversal stops as soon as `fn` returns
+// false, in which case Walk also returns false.
+func (s *Schema) Walk(fn func(*Schema) bool) bool {
+	return s.walk("#", func(_ string, v *Schema) bool {
+		return fn(v)
+	})
+}
+
+// walk is like Walk, but also passes the location of each schema
+// as a JSON pointer fragment relative to `ptr`
+func (s *Schema) walk(ptr string, fn func(string, *Schema) bool) bool {
+	if !fn(ptr, s) {
+		return false
+	}
+
+	for _, v := range s.subschemas() {
+		if !v.schema.walk(ptr+v.ptr, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// References returns the list of `$ref` values found in this
+// schema and its subschemas. Each reference is listed once,
+// in lexical order
+func (s *Schema) References() []string {
+	seen := make(map[string]struct{})
+	var list []string
+	s.Walk(func(v *Schema) bool {
+		if v.Reference == "" {
+			return true
+		}
+		if _, ok := seen[v.Reference]; !ok {
+			seen[v.Reference] = struct{}{}
+			list = append(list, v.Reference)
+		}
+		return true
+	})
+	sort.Strings(list)
+	return list
+}
+
+// CheckReferences attempts to resolve every `$ref` found in this
+// schema and its subschemas, and returns an error for each one
+// that could not be resolved. Errors are sorted by the location
+// of the offending schema. If all references can be resolved,
+// nil is returned.
+//
+// References that would be fetched by the ReferenceResolver are
+// not checked, so CheckReferences never goes over the network.
+// Results are not cached, so a failed check does not affect
+// later calls to Resolve
+func (s *Schema) CheckReferences() []error {
+	var locations []string
+	failures := make(map[string]error)
+	s.walk("#", func(ptr string, v *Schema) bool {
+		if v.Reference == "" {
+			return true
+		}
+		if _, _, ok := v.remoteReference(); ok {
+			return true
+		}
+		if _, err := v.resolve(v.Root()); err != nil {
+			locations = append(locations, ptr)
+			failures[ptr] = errors.Wrapf(err, "invalid reference at %s", strconv.Quote(ptr))
+		}
+		return true
+	})
+
+	if len(locations) == 0 {
+		return nil
 	}
 
-	// XXX Quite unimplemented
-	return nil, errors.Errorf("schema %s not found", strconv.Quote(id))
+	sort.Strings(locations)
+	list := make([]error, len(locations))
+	for i, ptr := range locations {
+		list[i] = failures[ptr]
+	}
+	return list
+}
+
+type subschema struct {
+	ptr    string
+	schema *Schema
+}
+
+var ptrEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func ptrToken(s string) string {
+	return "/" + ptrEscaper.Replace(s)
+}
+
+// subschemas returns the list of schemas directly nested
+// within this schema, along with their JSON pointer relative
+// to this schema
+func (s *Schema) subschemas() []subschema {
+	var list []subschema
+	for name, v := range s.Definitions {
+		list = append(list, subschema{"/definitions" + ptrToken(name), v})
+	}
+	if props := s.AdditionalProperties; props != nil && props.Schema != nil {
+		list = append(list, subschema{"/additionalProperties", props.Schema})
+	}
+	if items := s.AdditionalItems; items != nil && items.Schema != nil {
+		list = append(list, subschema{"/additionalItems", items.Schema})
+	}
+	if items := s.Items; items != nil {
+		if items.TupleMode {
+			for i, v := range items.Schemas {
+				list = append(list, subschema{"/items/" + strconv.Itoa(i), v})
+			}
+		} else if len(items.Schemas) > 0 {
+			list = append(list, subschema{"/items", items.Schemas[0]})
+		}
+	}
+	if v := s.Contains; v != nil {
+		list = append(list, subschema{"/contains", v})
+	}
+	for name, v := range s.Properties {
+		list = append(list, subschema{"/properties" + ptrToken(name), v})
+	}
+	for rx, v := range s.PatternProperties {
+		list = append(list, subschema{"/patternProperties" + ptrToken(rx.String()), v})
+	}
+	if v := s.PropertyNames; v != nil {
+		list = append(list, subschema{"/propertyNames", v})
+	}
+	for name, v := range s.Dependencies.Schemas {
+		list = append(list, subschema{"/dependencies" + ptrToken(name), v})
+	}
+	for i, v := range s.AllOf {
+		list = append(list, subschema{"/allOf/" + strconv.Itoa(i), v})
+	}
+	for i, v := range s.AnyOf {
+		list = append(list, subschema{"/anyOf/" + strconv.Itoa(i), v})
+	}
+	for i, v := range s.OneOf {
+		list = append(list, subschema{"/oneOf/" + strconv.Itoa(i), v})
+	}
+	if v := s.Not; v != nil {
+		list = append(list, subschema{"/not", v})
+	}
+	if v := s.If; v != nil {
+		list = append(list, subschema{"/if", v})
+	}
+	if v := s.Then; v != nil {
+		list = append(list, subschema{"/then", v})
+	}
+	if v := s.Else; v != nil {
+		list = append(list, subschema{"/else", v})
+	}
+	return list
 }
 
 // ResolveURL takes a url string, and resolves it if it's
@@ -201,6 +634,11 @@ func (s *Schema) IsResolved() bool {
 //
 // `ctx` is an optional context to resolve the reference with. If not
 // specified, the root schema as returned by `Root` will be used.
+// Only references resolved against the root schema are cached.
+//
+// Plain name fragments such as "#name" refer to the schema that
+// declares that name in `$anchor`. Validators built by the validator
+// package honor anchors too, as they resolve references with Bundle
 func (s *Schema) Resolve(ctx interface{}) (ref *Schema, err error) {
 	if s.Reference == "" {
 		return s, nil
@@ -217,10 +655,15 @@ func (s *Schema) Resolve(ctx interface{}) (ref *Schema, err error) {
 		}()
 	}
 
-	var thing interface{}
-	var ok bool
+	root := s.Root()
+	if ctx != nil && ctx != interface{}(root) {
+		// The cache only holds references resolved against our
+		// own document
+		return s.resolve(ctx)
+	}
+
 	s.resolveLock.Lock()
-	thing, ok = s.resolvedSchemas[s.Reference]
+	thing, ok := s.resolvedSchemas[s.Reference]
 	s.resolveLock.Unlock()
 
 	if ok {
@@ -229,45 +672,70 @@ func (s *Schema) Resolve(ctx interface{}) (ref *Schema, err error) {
 			if pdebug.Enabled {
 				pdebug.Printf("Cache HIT on '%s'", s.Reference)
 			}
-		} else {
-			if pdebug.Enabled {
-				pdebug.Printf("Negative Cache HIT on '%s'", s.Reference)
-			}
-			return nil, thing.(error)
+			return ref, nil
 		}
-	} else {
 		if pdebug.Enabled {
-			pdebug.Printf("Cache MISS on '%s'", s.Reference)
-		}
-		var err error
-		if ctx == nil {
-			ctx = s.Root()
-		}
-		thing, err := s.resolver.Resolve(ctx, s.Reference)
-		if err != nil {
-			err = errors.Wrapf(err, "failed to resolve reference %s", strconv.Quote(s.Reference))
-			s.resolveLock.Lock()
-			s.resolvedSchemas[s.Reference] = err
-			s.resolveLock.Unlock()
-			return nil, err
+			pdebug.Printf("Negative Cache HIT on '%s'", s.Reference)
 		}
+		return nil, thing.(error)
+	}
 
-		ref, ok = thing.(*Schema)
-		if !ok {
-			err = errors.Wrapf(err, "resolved reference %s is not a schema", strconv.Quote(s.Reference))
-			s.resolveLock.Lock()
-			s.resolvedSchemas[s.Reference] = err
-			s.resolveLock.Unlock()
-			return nil, err
-		}
-		s.resolveLock.Lock()
+	if pdebug.Enabled {
+		pdebug.Printf("Cache MISS on '%s'", s.Reference)
+	}
+	ref, err = s.resolve(root)
+	s.resolveLock.Lock()
+	if err != nil {
+		s.resolvedSchemas[s.Reference] = err
+	} else {
 		s.resolvedSchemas[s.Reference] = ref
-		s.resolveLock.Unlock()
+	}
+	s.resolveLock.Unlock()
+	return ref, err
+}
+
+// resolve resolves the reference against `ctx`, bypassing the cache.
+// Ids, the registry and the reference resolver only apply to
+// our own document, so they are not consulted for any other `ctx`
+func (s *Schema) resolve(ctx interface{}) (*Schema, error) {
+	own := ctx == interface{}(s.Root())
+
+	var thing interface{}
+	var err error
+	if root, ok := ctx.(*Schema); ok && s.Reference == "#" {
+		// A reference to the document itself, no need to
+		// go through the resolver
+		thing = root
+	} else if target, fragment, ok := s.idReference(); own && ok {
+		thing, err = resolveFragment(target, fragment)
+	} else if name, ok := anchorName(s.Reference); ok {
+		thing, err = resolveAnchor(ctx, name)
+	} else if uri, ok := s.registryReference(); own && ok {
+		thing, err = s.Root().registry.Resolve(uri)
+	} else if u, fragment, ok := s.remoteReference(); own && ok {
+		thing, err = s.resolveRemote(u, fragment)
+	} else {
+		thing, err = s.resolver.Resolve(ctx, s.Reference)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to resolve reference %s", strconv.Quote(s.Reference))
 	}
 
+	ref, ok := thing.(*Schema)
+	if !ok {
+		return nil, errors.Errorf("resolved reference %s is not a schema", strconv.Quote(s.Reference))
+	}
 	return ref, nil
 }
 
+func (s *Schema) resolveRemote(u *url.URL, fragment string) (*Schema, error) {
+	doc, err := s.Root().refResolver.Resolve(u)
+	if err != nil {
+		return nil, err
+	}
+	return resolveFragment(doc, fragment)
+}
+
 // IsPropRequired can be used to query this schema if a
 // given property name is required.
 func (s *Schema) IsPropRequired(pname string) bool {
@@ -279,7 +747,157 @@ func (s *Schema) IsPropRequired(pname string) bool {
 	return false
 }
 
-// Scope returns the scope ID for this schema
+// HasDefault returns true if this schema has a default value. Unlike
+// checking Default against nil, this is also true for a schema that
+// was parsed with `"default": null`
+func (s *Schema) HasDefault() bool {
+	return s.hasDefault || s.Default != nil
+}
+
+// DefaultString returns the default value for this schema if
+// it is a string. The second return value is false if there is no
+// default value, or if it is not a string
+func (s *Schema) DefaultString() (string, bool) {
+	v, ok := s.Default.(string)
+	return v, ok
+}
+
+// DefaultFloat returns the default value for this schema as a
+// float64, if it is a number. The second return value is false if
+// there is no default value, or if it is not a number
+func (s *Schema) DefaultFloat() (float64, bool) {
+	if s.Default == nil {
+		return 0, false
+	}
+
+	rv := reflect.ValueOf(s.Default)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	}
+
+	if n, ok := s.Default.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// DefaultBool returns the default value for this schema if
+// it is a boolean. The second return value is false if there is no
+// default value, or if it is not a boolean
+func (s *Schema) DefaultBool() (bool, bool) {
+	v, ok := s.Default.(bool)
+	return v, ok
+}
+
+// RequiredPaths returns the paths listed in the "requiredPaths"
+// vendor extension, such as "address.city", each naming a nested
+// property that must be present. The extension is kept in Extras, so
+// it is written back by MarshalJSON, but it is not enforced by the
+// validator package. nil is returned if the schema does not use it
+func (s *Schema) RequiredPaths() ([]string, error) {
+	v, ok := s.Extras["requiredPaths"]
+	if !ok {
+		return nil, nil
+	}
+	if l, ok := v.([]string); ok {
+		return l, nil
+	}
+
+	var l []string
+	if err := convertStringList(&l, v); err != nil {
+		return nil, errors.Wrap(err, "failed to read 'requiredPaths'")
+	}
+	return l, nil
+}
+
+// EffectiveDefaults returns the default values that would be used
+// to fill in properties that are absent from `v`, keyed by the JSON
+// pointer of each property. Objects and arrays that are present in `v`
+// are inspected recursively, using "properties" and "items"
+// respectively. `v` is expected to be in the form produced by
+// encoding/json, that is objects must be map[string]interface{} and
+// arrays must be []interface{}; values of any other type, such as
+// structs, are not inspected. `v` is not modified
+func (s *Schema) EffectiveDefaults(v interface{}) (map[string]interface{}, error) {
+	defaults := make(map[string]interface{})
+	if err := s.collectDefaults("", v, defaults); err != nil {
+		return nil, err
+	}
+	return defaults, nil
+}
+
+func (s *Schema) collectDefaults(ptr string, v interface{}, defaults map[string]interface{}) error {
+	def, err := s.Resolve(nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to resolve schema for %s", strconv.Quote(ptr))
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return def.collectPropertyDefaults(ptr, val, defaults)
+	case []interface{}:
+		return def.collectItemDefaults(ptr, val, defaults)
+	}
+	return nil
+}
+
+func (s *Schema) collectPropertyDefaults(ptr string, m map[string]interface{}, defaults map[string]interface{}) error {
+	for name, pdef := range s.Properties {
+		pptr := ptr + ptrToken(name)
+		pv, ok := m[name]
+		if ok {
+			if err := pdef.collectDefaults(pptr, pv, defaults); err != nil {
+				return err
+			}
+			continue
+		}
+
+		resolved, err := pdef.Resolve(nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to resolve schema for %s", strconv.Quote(pptr))
+		}
+		if resolved.HasDefault() {
+			defaults[pptr] = resolved.Default
+		}
+	}
+	return nil
+}
+
+func (s *Schema) collectItemDefaults(ptr string, l []interface{}, defaults map[string]interface{}) error {
+	items := s.Items
+	if items == nil || len(items.Schemas) == 0 {
+		return nil
+	}
+
+	for i, iv := range l {
+		var idef *Schema
+		switch {
+		case !items.TupleMode:
+			idef = items.Schemas[0]
+		case i < len(items.Schemas):
+			idef = items.Schemas[i]
+		case s.AdditionalItems != nil && s.AdditionalItems.Schema != nil:
+			idef = s.AdditionalItems.Schema
+		default:
+			continue
+		}
+
+		if err := idef.collectDefaults(ptr+"/"+strconv.Itoa(i), iv, defaults); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scope returns the scope ID for this schema: its own id if it
+// has one, or the scope of its parent otherwise. The id is returned
+// as written, see ResolvedScope for the absolute scope
 func (s *Schema) Scope() string {
 	if pdebug.Enabled {
 		g := pdebug.IPrintf("START Schema.Scope")
@@ -294,3 +912,45 @@ func (s *Schema) Scope() string {
 
 	return s.parent.Scope()
 }
+
+// ResolvedScope returns the resolution scope for this schema. Unlike
+// Scope, a relative id is resolved against the scope of the parent
+// schema, or for the root schema against the base URL it was read
+// from, if any
+func (s *Schema) ResolvedScope() string {
+	if pdebug.Enabled {
+		g := pdebug.IPrintf("START Schema.ResolvedScope")
+		defer g.IRelease("END Schema.ResolvedScope")
+	}
+
+	var scope string
+	if s.parent != nil {
+		scope = s.parent.ResolvedScope()
+	} else if s.base != "" {
+		// The root's id may be relative to where it was read from
+		scope = s.base
+	} else {
+		if pdebug.Enabled {
+			pdebug.Printf("Returning id '%s'", s.ID)
+		}
+		return s.ID
+	}
+
+	if s.ID == "" {
+		return scope
+	}
+
+	// Our id may be relative to the scope of our parent
+	base, err := url.Parse(scope)
+	if err != nil {
+		return s.ID
+	}
+	u, err := base.Parse(s.ID)
+	if err != nil {
+		return s.ID
+	}
+	if pdebug.Enabled {
+		pdebug.Printf("Returning id '%s' (resolved from '%s')", u, s.ID)
+	}
+	return u.String()
+}
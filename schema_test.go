@@ -38,12 +38,17 @@ func TestValidate(t *testing.T) {
 		"anyof",
 		"array",
 		"arraylength",
+		"arraymaxitems",
+		"arrayminitems",
 		"arraytuple",
 		"arraytuple_disallow_additional",
 		"arrayunique",
 		"boolean",
 		"business",
+		"enum",
 		"integer",
+		"linkedlist",
+		"nestedarray",
 		"not",
 		"null",
 		"numrange",
@@ -53,8 +58,10 @@ func TestValidate(t *testing.T) {
 		"objectpropsize",
 		"objectproprequired",
 		"oneof",
+		"patternflags",
 		"strlen",
 		"strpattern",
+		"untypedproperties",
 	}
 	for _, name := range tests {
 		schemaf := filepath.Join("test", name+".json")
@@ -74,7 +81,7 @@ func TestValidate(t *testing.T) {
 			if !assert.NoError(t, err, "os.Open(%s) should succeed", passf) {
 				return
 			}
-			var m map[string]interface{} // XXX should test against structs
+			var m interface{} // XXX should test against structs
 			if !assert.NoError(t, json.NewDecoder(passin).Decode(&m), "json.Decode should succeed") {
 				return
 			}
@@ -92,7 +99,7 @@ func TestValidate(t *testing.T) {
 			if !assert.NoError(t, err, "os.Open(%s) should succeed", failf) {
 				return
 			}
-			var m map[string]interface{} // XXX should test against structs
+			var m interface{} // XXX should test against structs
 			if !assert.NoError(t, json.NewDecoder(failin).Decode(&m), "json.Decode should succeed") {
 				return
 			}
@@ -121,3 +128,772 @@ func TestExtras(t *testing.T) {
 		}
 	}
 }
+
+func TestPatternInlineFlags(t *testing.T) {
+	const src = `{
+  "pattern": "(?i)^abc$",
+  "patternProperties": {
+    "(?i)^abc$": { "type": "integer" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	if !assert.Equal(t, "(?i)^abc$", s.Pattern.String(), "pattern should be used as-is") {
+		return
+	}
+	if !assert.True(t, s.Pattern.MatchString("ABC"), "pattern should match 'ABC'") {
+		return
+	}
+
+	if !assert.Len(t, s.PatternProperties, 1, "there should be 1 pattern property") {
+		return
+	}
+	for rx := range s.PatternProperties {
+		if !assert.Equal(t, "(?i)^abc$", rx.String(), "pattern property should be used as-is") {
+			return
+		}
+		if !assert.True(t, rx.MatchString("ABC"), "pattern property should match 'ABC'") {
+			return
+		}
+	}
+}
+
+func TestResolveAnchor(t *testing.T) {
+	const src = `{
+  "definitions": {
+    "name": {
+      "$anchor": "name",
+      "type": "string"
+    }
+  },
+  "properties": {
+    "first": { "$ref": "#name" },
+    "last": { "$ref": "#nonexistent" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	if !assert.Equal(t, "name", s.Definitions["name"].Anchor, "$anchor should be parsed") {
+		return
+	}
+
+	resolved, err := s.Properties["first"].Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed") {
+		return
+	}
+	if !assert.Equal(t, s.Definitions["name"], resolved, "Resolve should return the anchored schema") {
+		return
+	}
+
+	_, err = s.Properties["last"].Resolve(nil)
+	if !assert.Error(t, err, "Resolve should fail for unknown anchor") {
+		return
+	}
+}
+
+func TestResolveAnchorScope(t *testing.T) {
+	const src = `{
+  "id": "http://example.com/root.json",
+  "definitions": {
+    "a": {
+      "id": "a.json",
+      "definitions": {
+        "item": { "$anchor": "item", "type": "string" }
+      },
+      "properties": {
+        "p": { "$ref": "#item" }
+      }
+    },
+    "b": {
+      "id": "b.json",
+      "definitions": {
+        "item": { "$anchor": "item", "type": "integer" }
+      },
+      "properties": {
+        "p": { "$ref": "#item" }
+      }
+    }
+  },
+  "properties": {
+    "p": { "$ref": "#item" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	for _, name := range []string{"a", "b"} {
+		def := s.Definitions[name]
+		resolved, err := def.Properties["p"].Resolve(nil)
+		if !assert.NoError(t, err, "Resolve should succeed in '%s'", name) {
+			return
+		}
+		if !assert.Equal(t, def.Definitions["item"], resolved, "anchor should resolve within '%s'", name) {
+			return
+		}
+	}
+
+	_, err = s.Properties["p"].Resolve(nil)
+	if !assert.Error(t, err, "Resolve should not find anchors of other resources") {
+		return
+	}
+}
+
+func TestResolveRoot(t *testing.T) {
+	s, err := readSchema(filepath.Join("test", "linkedlist.json"))
+	if !assert.NoError(t, err, "readSchema should succeed") {
+		return
+	}
+
+	next := s.Properties["next"]
+	if !assert.Len(t, next.AnyOf, 2, "'next' should have 2 anyOf entries") {
+		return
+	}
+
+	resolved, err := next.AnyOf[1].Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed") {
+		return
+	}
+	if !assert.Equal(t, s, resolved, "'#' should resolve to the root schema") {
+		return
+	}
+}
+
+func TestReferences(t *testing.T) {
+	const src = `{
+  "definitions": {
+    "name": { "type": "string" },
+    "age": { "$ref": "#/definitions/positive" },
+    "positive": { "type": "integer", "minimum": 0 }
+  },
+  "properties": {
+    "first": { "$ref": "#/definitions/name" },
+    "last": { "$ref": "#/definitions/name" },
+    "age": { "$ref": "#/definitions/age" },
+    "children": {
+      "type": "array",
+      "items": { "$ref": "#" }
+    }
+  },
+  "not": { "$ref": "http://example.com/schema.json#/definitions/forbidden" }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	expected := []string{
+		"#",
+		"#/definitions/age",
+		"#/definitions/name",
+		"#/definitions/positive",
+		"http://example.com/schema.json#/definitions/forbidden",
+	}
+	if !assert.Equal(t, expected, s.References(), "References should return every $ref once") {
+		return
+	}
+}
+
+func TestCheckReferences(t *testing.T) {
+	const valid = `{
+  "definitions": {
+    "name": { "type": "string" }
+  },
+  "properties": {
+    "name": { "$ref": "#/definitions/name" },
+    "children": {
+      "type": "array",
+      "items": { "$ref": "#" }
+    }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(valid))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if !assert.Empty(t, s.CheckReferences(), "CheckReferences should not report errors") {
+		return
+	}
+
+	const dangling = `{
+  "definitions": {
+    "name": { "type": "string" }
+  },
+  "properties": {
+    "name": { "$ref": "#/definitions/name" },
+    "age": { "$ref": "#/definitions/age" },
+    "title": { "$ref": "#/title" }
+  },
+  "title": "not a schema"
+}`
+	s, err = schema.Read(strings.NewReader(dangling))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	errs := s.CheckReferences()
+	if !assert.Len(t, errs, 2, "CheckReferences should report 2 errors") {
+		return
+	}
+	if !assert.Contains(t, errs[0].Error(), "#/properties/age", "error should contain the location of the reference") {
+		return
+	}
+	if !assert.Contains(t, errs[1].Error(), "#/properties/title", "error should contain the location of the reference") {
+		return
+	}
+
+	// Failures are not cached
+	s.Definitions["age"] = schema.NewInteger()
+	if _, err := s.Properties["age"].Resolve(nil); !assert.NoError(t, err, "Resolve should succeed once the definition exists") {
+		return
+	}
+}
+
+func TestDefaultValues(t *testing.T) {
+	const src = `{
+  "properties": {
+    "name": { "type": "string", "default": "John" },
+    "age": { "type": "integer", "default": 42 },
+    "admin": { "type": "boolean", "default": true },
+    "nodefault": { "type": "string" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	if v, ok := s.Properties["name"].DefaultString(); !assert.True(t, ok, "DefaultString should succeed") || !assert.Equal(t, "John", v, "DefaultString should return the default") {
+		return
+	}
+	if v, ok := s.Properties["age"].DefaultFloat(); !assert.True(t, ok, "DefaultFloat should succeed") || !assert.Equal(t, float64(42), v, "DefaultFloat should return the default") {
+		return
+	}
+	if v, ok := s.Properties["admin"].DefaultBool(); !assert.True(t, ok, "DefaultBool should succeed") || !assert.True(t, v, "DefaultBool should return the default") {
+		return
+	}
+
+	for _, name := range []string{"age", "admin", "nodefault"} {
+		if _, ok := s.Properties[name].DefaultString(); !assert.False(t, ok, "DefaultString should fail for '%s'", name) {
+			return
+		}
+	}
+	for _, name := range []string{"name", "admin", "nodefault"} {
+		if _, ok := s.Properties[name].DefaultFloat(); !assert.False(t, ok, "DefaultFloat should fail for '%s'", name) {
+			return
+		}
+	}
+	for _, name := range []string{"name", "age", "nodefault"} {
+		if _, ok := s.Properties[name].DefaultBool(); !assert.False(t, ok, "DefaultBool should fail for '%s'", name) {
+			return
+		}
+	}
+
+	hand := schema.New()
+	hand.Default = 10
+	if v, ok := hand.DefaultFloat(); !assert.True(t, ok, "DefaultFloat should succeed for int") || !assert.Equal(t, float64(10), v, "DefaultFloat should convert int") {
+		return
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	inputs := map[string]string{
+		"array": `[
+  { "title": "first", "type": "string" },
+  { "title": "second", "type": "integer" }
+]
+`,
+		"ndjson": `{ "title": "first", "type": "string" }
+{ "title": "second", "type": "integer" }
+`,
+	}
+
+	for name, src := range inputs {
+		t.Logf("Testing %s input", name)
+		list, err := schema.ReadAll(strings.NewReader(src))
+		if !assert.NoError(t, err, "schema.ReadAll should succeed") {
+			return
+		}
+		if !assert.Len(t, list, 2, "schema.ReadAll should return 2 schemas") {
+			return
+		}
+		if !assert.Equal(t, "first", list[0].Title, "first schema should be parsed") {
+			return
+		}
+		if !assert.Equal(t, "second", list[1].Title, "second schema should be parsed") {
+			return
+		}
+	}
+
+	_, err := schema.ReadAll(strings.NewReader(`{ "type": "string" } { "type": 1 }`))
+	if !assert.Error(t, err, "schema.ReadAll should fail on invalid schema") {
+		return
+	}
+
+	for _, src := range []string{`[{}] garbage`, `[{}] [{}]`, `[{}] {}`} {
+		_, err := schema.ReadAll(strings.NewReader(src))
+		if !assert.Error(t, err, "schema.ReadAll should fail on data after the array: %s", src) {
+			return
+		}
+	}
+}
+
+func TestEffectiveDefaults(t *testing.T) {
+	const src = `{
+  "definitions": {
+    "country": { "type": "string", "default": "JP" }
+  },
+  "type": "object",
+  "properties": {
+    "name": { "type": "string", "default": "anonymous" },
+    "age": { "type": "integer", "default": 20 },
+    "email": { "type": "string" },
+    "address": {
+      "type": "object",
+      "properties": {
+        "city": { "type": "string", "default": "Tokyo" },
+        "country": { "$ref": "#/definitions/country" }
+      }
+    },
+    "nickname": { "type": [ "string", "null" ], "default": null },
+    "phones": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "kind": { "type": "string", "default": "mobile" },
+          "number": { "type": "string" }
+        }
+      }
+    },
+    "point": {
+      "type": "array",
+      "items": [
+        { "type": "object", "properties": { "x": { "default": 0 } } },
+        { "type": "object", "properties": { "y": { "default": 0 } } }
+      ]
+    }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	if !assert.True(t, s.Properties["nickname"].HasDefault(), "null default should be reported by HasDefault") {
+		return
+	}
+	if !assert.False(t, s.Properties["email"].HasDefault(), "missing default should not be reported by HasDefault") {
+		return
+	}
+
+	v := map[string]interface{}{
+		"age": 42,
+		"address": map[string]interface{}{
+			"city": "Osaka",
+		},
+		"phones": []interface{}{
+			map[string]interface{}{"number": "123"},
+			map[string]interface{}{"kind": "home", "number": "456"},
+		},
+		"point": []interface{}{
+			map[string]interface{}{},
+			map[string]interface{}{"y": 1.0},
+			map[string]interface{}{},
+		},
+	}
+	defaults, err := s.EffectiveDefaults(v)
+	if !assert.NoError(t, err, "EffectiveDefaults should succeed") {
+		return
+	}
+
+	expected := map[string]interface{}{
+		"/name":            "anonymous",
+		"/address/country": "JP",
+		"/nickname":        nil,
+		"/phones/0/kind":   "mobile",
+		"/point/0/x":       0.0,
+	}
+	if !assert.Equal(t, expected, defaults, "EffectiveDefaults should return defaults for absent properties") {
+		return
+	}
+	if !assert.Len(t, v, 4, "EffectiveDefaults should not modify the value") {
+		return
+	}
+}
+
+func TestResolveNestedReference(t *testing.T) {
+	const src = `{
+  "definitions": {
+    "name": { "type": "string" }
+  },
+  "properties": {
+    "allof": {
+      "allOf": [
+        { "anyOf": [ { "$ref": "#/definitions/name" } ] }
+      ]
+    },
+    "items": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "name": { "$ref": "#/definitions/name" }
+        }
+      }
+    },
+    "patterns": {
+      "patternProperties": {
+        "^x-": { "$ref": "#/definitions/name" }
+      }
+    }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	refs := map[string]*schema.Schema{
+		"allOf": s.Properties["allof"].AllOf[0].AnyOf[0],
+		"items": s.Properties["items"].Items.Schemas[0].Properties["name"],
+	}
+	for _, v := range s.Properties["patterns"].PatternProperties {
+		refs["patternProperties"] = v
+	}
+
+	for name, ref := range refs {
+		if !assert.Equal(t, s, ref.Root(), "Root() of reference in %s should be the top level schema", name) {
+			return
+		}
+		resolved, err := ref.Resolve(nil)
+		if !assert.NoError(t, err, "Resolve should succeed for reference in %s", name) {
+			return
+		}
+		if !assert.Equal(t, s.Definitions["name"], resolved, "reference in %s should resolve to the top level definition", name) {
+			return
+		}
+	}
+}
+
+func TestResolveScopedID(t *testing.T) {
+	const src = `{
+  "id": "http://example.com/root.json",
+  "definitions": {
+    "addr": {
+      "id": "address",
+      "type": "object",
+      "properties": {
+        "zip": { "type": "string" },
+        "country": { "$ref": "#/definitions/country" }
+      },
+      "definitions": {
+        "country": { "type": "string", "enum": [ "JP", "US" ] }
+      }
+    }
+  },
+  "properties": {
+    "home": { "$ref": "address" },
+    "zip": { "$ref": "address#/properties/zip" },
+    "absolute": { "$ref": "http://example.com/address" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	addr := s.Definitions["addr"]
+	if !assert.Equal(t, "http://example.com/address", addr.ResolvedScope(), "relative id should be resolved against the parent scope") {
+		return
+	}
+	if !assert.Equal(t, "address", addr.Scope(), "Scope should return the id as written") {
+		return
+	}
+
+	expected := map[string]*schema.Schema{
+		"home":     addr,
+		"zip":      addr.Properties["zip"],
+		"absolute": addr,
+	}
+	for name, target := range expected {
+		resolved, err := s.Properties[name].Resolve(nil)
+		if !assert.NoError(t, err, "Resolve should succeed for '%s'", name) {
+			return
+		}
+		if !assert.Equal(t, target, resolved, "'%s' should resolve to the schema with the matching id", name) {
+			return
+		}
+	}
+
+	// Fragments are relative to the closest id
+	resolved, err := addr.Properties["country"].Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed for 'country'") {
+		return
+	}
+	if !assert.Equal(t, addr.Definitions["country"], resolved, "'country' should resolve relative to the 'address' scope") {
+		return
+	}
+}
+
+func TestResolveID(t *testing.T) {
+	const src = `{
+  "id": "http://example.com/root.json",
+  "definitions": {
+    "A": { "id": "#foo", "type": "integer" },
+    "B": {
+      "id": "other.json",
+      "definitions": {
+        "X": { "id": "#bar", "type": "string" },
+        "Y": { "id": "t/inner.json", "type": "boolean" }
+      }
+    }
+  },
+  "properties": {
+    "a": { "$ref": "#foo" },
+    "x": { "$ref": "other.json#bar" },
+    "y": { "$ref": "http://example.com/t/inner.json" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	b := s.Definitions["B"]
+	expected := map[string]*schema.Schema{
+		"http://example.com/root.json":      s,
+		"#foo":                              s.Definitions["A"],
+		"other.json":                        b,
+		"http://example.com/other.json#bar": b.Definitions["X"],
+		"http://example.com/t/inner.json":   b.Definitions["Y"],
+	}
+	for id, target := range expected {
+		found, err := s.ResolveID(id)
+		if !assert.NoError(t, err, "ResolveID should succeed for '%s'", id) {
+			return
+		}
+		if !assert.Equal(t, target, found, "ResolveID should find the schema for '%s'", id) {
+			return
+		}
+	}
+
+	// Relative ids are resolved against the scope of the receiver
+	found, err := b.Definitions["X"].ResolveID("#bar")
+	if !assert.NoError(t, err, "ResolveID should succeed for '#bar'") {
+		return
+	}
+	if !assert.Equal(t, b.Definitions["X"], found, "ResolveID should find '#bar' within 'other.json'") {
+		return
+	}
+
+	if _, err := s.ResolveID("#bar"); !assert.Error(t, err, "ResolveID should fail for '#bar' outside 'other.json'") {
+		return
+	}
+
+	refs := map[string]*schema.Schema{
+		"a": s.Definitions["A"],
+		"x": b.Definitions["X"],
+		"y": b.Definitions["Y"],
+	}
+	for name, target := range refs {
+		resolved, err := s.Properties[name].Resolve(nil)
+		if !assert.NoError(t, err, "Resolve should succeed for '%s'", name) {
+			return
+		}
+		if !assert.Equal(t, target, resolved, "'%s' should resolve to the schema with the matching id", name) {
+			return
+		}
+	}
+}
+
+func TestReadWithBase(t *testing.T) {
+	const address = `{
+  "definitions": {
+    "zip": { "type": "string", "pattern": "^[0-9]{5}$" }
+  },
+  "type": "object",
+  "properties": {
+    "zip": { "$ref": "#/definitions/zip" }
+  }
+}`
+	const person = `{
+  "type": "object",
+  "properties": {
+    "zip": { "$ref": "address.json#/definitions/zip" },
+    "self": { "$ref": "#" }
+  }
+}`
+
+	addr, err := schema.ReadWithBase(strings.NewReader(address), "http://example.com/schemas/address.json")
+	if !assert.NoError(t, err, "schema.ReadWithBase should succeed") {
+		return
+	}
+	s, err := schema.ReadWithBase(strings.NewReader(person), "http://example.com/schemas/person.json")
+	if !assert.NoError(t, err, "schema.ReadWithBase should succeed") {
+		return
+	}
+	if !assert.Equal(t, "http://example.com/schemas/person.json", s.ResolvedScope(), "scope should be the base URL") {
+		return
+	}
+
+	r := schema.NewRegistry()
+	for _, v := range []*schema.Schema{addr, s} {
+		if !assert.NoError(t, r.Add(v), "Registry.Add should succeed") {
+			return
+		}
+	}
+
+	expected := map[string]*schema.Schema{
+		"zip":  addr.Definitions["zip"],
+		"self": s,
+	}
+	for name, target := range expected {
+		resolved, err := s.Properties[name].Resolve(nil)
+		if !assert.NoError(t, err, "Resolve should succeed for '%s'", name) {
+			return
+		}
+		if !assert.Equal(t, target, resolved, "'%s' should resolve relative to the base URL", name) {
+			return
+		}
+	}
+
+	// The id is resolved against the base URL
+	s, err = schema.ReadWithBase(strings.NewReader(`{"id":"v2/person.json"}`), "http://example.com/schemas/person.json")
+	if !assert.NoError(t, err, "schema.ReadWithBase should succeed") {
+		return
+	}
+	if !assert.Equal(t, "http://example.com/schemas/v2/person.json", s.ResolvedScope(), "relative id should be resolved against the base URL") {
+		return
+	}
+
+	_, err = schema.ReadWithBase(strings.NewReader(`{}`), "http://[::1")
+	if !assert.Error(t, err, "schema.ReadWithBase should fail for an invalid base URL") {
+		return
+	}
+}
+
+func TestResolveWithContext(t *testing.T) {
+	const src = `{
+  "id": "http://example.com/root",
+  "definitions": {
+    "name": { "type": "string" }
+  },
+  "properties": {
+    "name": { "$ref": "#/definitions/name" }
+  }
+}`
+	const other = `{
+  "definitions": {
+    "name": { "type": "integer" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	ctx, err := schema.Read(strings.NewReader(other))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	ref := s.Properties["name"]
+	resolved, err := ref.Resolve(ctx)
+	if !assert.NoError(t, err, "Resolve should succeed") {
+		return
+	}
+	if !assert.Equal(t, ctx.Definitions["name"], resolved, "Resolve should use the given context") {
+		return
+	}
+
+	resolved, err = ref.Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed") {
+		return
+	}
+	if !assert.Equal(t, s.Definitions["name"], resolved, "Resolve should use the root without a context") {
+		return
+	}
+
+	resolved, err = ref.Resolve(ctx)
+	if !assert.NoError(t, err, "Resolve should succeed") {
+		return
+	}
+	if !assert.Equal(t, ctx.Definitions["name"], resolved, "Resolve should not use the cache for another context") {
+		return
+	}
+}
+
+func TestDuplicateIdentifiers(t *testing.T) {
+	inputs := map[string]string{
+		"id": `{
+  "id": "http://example.com/root",
+  "definitions": {
+    "a": { "id": "item" },
+    "b": { "id": "http://example.com/item" }
+  }
+}`,
+		"anchor": `{
+  "definitions": {
+    "a": { "$anchor": "item" },
+    "b": { "$anchor": "item" }
+  }
+}`,
+	}
+	for name, src := range inputs {
+		t.Logf("Testing duplicate %s", name)
+		_, err := schema.Read(strings.NewReader(src))
+		if !assert.Error(t, err, "schema.Read should fail") {
+			return
+		}
+	}
+
+	// The same anchor may be used within different scopes
+	const src = `{
+  "id": "http://example.com/root",
+  "definitions": {
+    "a": { "id": "a", "definitions": { "item": { "$anchor": "item", "type": "string" } } },
+    "b": { "id": "b", "definitions": { "item": { "$anchor": "item", "type": "integer" } } }
+  },
+  "properties": {
+    "b": { "$ref": "b#item" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	resolved, err := s.Properties["b"].Resolve(nil)
+	if !assert.NoError(t, err, "Resolve should succeed") {
+		return
+	}
+	if !assert.Equal(t, s.Definitions["b"].Definitions["item"], resolved, "Resolve should use the anchor within the referenced scope") {
+		return
+	}
+}
+
+func TestResolveIDAfterModification(t *testing.T) {
+	s, err := schema.Read(strings.NewReader(`{ "id": "http://example.com/root" }`))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	// Schemas added after reading are not indexed, and are found
+	// by walking the tree instead
+	added := schema.NewString()
+	added.ID = "http://example.com/added"
+	s.Definitions = map[string]*schema.Schema{"added": added}
+
+	found, err := s.ResolveID("http://example.com/added")
+	if !assert.NoError(t, err, "ResolveID should succeed") {
+		return
+	}
+	if !assert.Equal(t, added, found, "ResolveID should find the added schema") {
+		return
+	}
+}
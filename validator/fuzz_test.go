@@ -0,0 +1,45 @@
+//go:build go1.18
+// +build go1.18
+
+package validator_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat/go-jsschema"
+	"github.com/lestrrat/go-jsschema/validator"
+)
+
+func FuzzValidate(f *testing.F) {
+	const src = `{
+  "type": "object",
+  "properties": {
+    "name": { "type": "string", "minLength": 1, "pattern": "^[a-z]+$" },
+    "age": { "type": "integer", "minimum": 0 },
+    "tags": { "type": "array", "items": { "type": "string" }, "uniqueItems": true }
+  },
+  "required": [ "name" ]
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if err != nil {
+		f.Fatalf("failed to read schema: %s", err)
+	}
+	v := validator.New(s)
+
+	f.Add([]byte(`{"name":"john","age":42}`))
+	f.Add([]byte(`{"name":"","tags":["a","a"]}`))
+	f.Add([]byte(`{"age":-1}`))
+	f.Add([]byte(`[1,"two",null]`))
+	f.Add([]byte(`"string"`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var x interface{}
+		if err := json.Unmarshal(data, &x); err != nil {
+			return
+		}
+		// Only checking that validation does not panic
+		v.Validate(x)
+	})
+}
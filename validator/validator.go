@@ -1,6 +1,11 @@
 package validator
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
 	"sync"
 
 	"github.com/lestrrat/go-jsschema"
@@ -29,15 +34,74 @@ func New(s *schema.Schema) *Validator {
 // You usually should NOT use this method (the main
 // reason this is exposed is for benchmarking), as it
 // is automatically called when `Validate` is called.
+//
+// References are resolved up front with schema.Bundle, so that
+// references to ids, anchors and to schemas in a schema.Registry
+// or fetched by a schema.ReferenceResolver are all honored.
+//
+// Compile fails if the schema, or any schema it refers to, uses
+// keywords that go-jsval would silently ignore, such as "$dynamicRef".
 func (v *Validator) Compile() (*jsval.JSVal, error) {
+	bundled, err := v.schema.Bundle()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build validator")
+	}
+
+	// Check the bundle, as it also holds the schemas that were
+	// pulled in from a registry or a resolver
+	bundled.Walk(func(s *schema.Schema) bool {
+		err = check(s)
+		return err == nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build validator")
+	}
+
 	b := builder.New()
-	jsv, err := b.Build(v.schema)
+	jsv, err := b.Build(bundled)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build validator")
 	}
 	return jsv, nil
 }
 
+// check returns an error if go-jsval cannot build a correct
+// validator from `s`. Schemas that were parsed have been checked
+// already, but schemas may also be assembled by hand
+func check(s *schema.Schema) error {
+	if keyword := unsupportedKeyword(s); keyword != "" {
+		return errors.Errorf("%s is not supported", strconv.Quote(keyword))
+	}
+	if n := s.MultipleOf; n.Initialized && !(n.Val > 0 && !math.IsInf(n.Val, 1)) {
+		return errors.Errorf("multipleOf must be a finite number greater than 0, got %v", n.Val)
+	}
+	return nil
+}
+
+// unsupportedKeyword returns the first keyword used by `s` that
+// go-jsval does not enforce, or an empty string
+func unsupportedKeyword(s *schema.Schema) string {
+	switch {
+	case s.Const.Initialized:
+		return "const"
+	case s.Contains != nil:
+		return "contains"
+	case s.If != nil:
+		return "if"
+	case s.Then != nil:
+		return "then"
+	case s.Else != nil:
+		return "else"
+	case s.PropertyNames != nil:
+		return "propertyNames"
+	case s.RecursiveReference != "":
+		return "$recursiveRef"
+	case s.DynamicReference != "":
+		return "$dynamicRef"
+	}
+	return ""
+}
+
 func (v *Validator) validator() (*jsval.JSVal, error) {
 	v.lock.Lock()
 	defer v.lock.Unlock()
@@ -53,7 +117,17 @@ func (v *Validator) validator() (*jsval.JSVal, error) {
 }
 
 // Validate takes an arbitrary piece of data and
-// validates it against the schema.
+// validates it against the schema. The schema is compiled,
+// and its references resolved, only on the first call, so
+// reuse the same Validator on hot paths.
+//
+// `x` is inspected as is, by go-jsval. It is not converted to its
+// JSON form first, so values that implement json.Marshaler or
+// encoding.TextMarshaler, such as net.IP or time.Time, are not
+// validated as the strings they encode to. Likewise json.RawMessage
+// values, including those in a map[string]json.RawMessage, are seen
+// as byte slices rather than the documents they hold. To validate the
+// JSON form of such values, marshal them and use ValidateBytes.
 func (v *Validator) Validate(x interface{}) error {
 	jsv, err := v.validator()
 	if err != nil {
@@ -61,3 +135,41 @@ func (v *Validator) Validate(x interface{}) error {
 	}
 	return jsv.Validate(x)
 }
+
+// ValidateBytes decodes the JSON document in `data` and validates
+// it against the schema. Anything but whitespace following the
+// document is treated as malformed input.
+func (v *Validator) ValidateBytes(data []byte) error {
+	x, err := decodeDocument(data)
+	if err != nil {
+		return err
+	}
+	return v.Validate(x)
+}
+
+// ValidateAndDecode decodes the JSON document in `data`, validates
+// it against the schema, and if it is valid, decodes it into `out`.
+// `out` is left untouched if validation fails.
+func (v *Validator) ValidateAndDecode(data []byte, out interface{}) error {
+	if err := v.ValidateBytes(data); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return errors.Wrap(err, "failed to decode data")
+	}
+	return nil
+}
+
+func decodeDocument(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var x interface{}
+	if err := dec.Decode(&x); err != nil {
+		return nil, errors.Wrap(err, "failed to decode data")
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("failed to decode data: unexpected data after JSON document")
+	}
+	return x, nil
+}
@@ -0,0 +1,334 @@
+package validator_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat/go-jsschema"
+	"github.com/lestrrat/go-jsschema/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAndDecode(t *testing.T) {
+	const src = `{
+  "type": "object",
+  "properties": {
+    "name": { "type": "string", "minLength": 1 },
+    "age": { "type": "integer", "minimum": 0 }
+  },
+  "required": [ "name" ]
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	v := validator.New(s)
+
+	type person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	var p person
+	if !assert.NoError(t, v.ValidateAndDecode([]byte(`{"name":"John","age":42}`), &p), "ValidateAndDecode should succeed") {
+		return
+	}
+	if !assert.Equal(t, person{Name: "John", Age: 42}, p, "ValidateAndDecode should decode the data") {
+		return
+	}
+
+	var invalid person
+	if !assert.Error(t, v.ValidateAndDecode([]byte(`{"age":-1}`), &invalid), "ValidateAndDecode should fail") {
+		return
+	}
+	if !assert.Equal(t, person{}, invalid, "ValidateAndDecode should not decode invalid data") {
+		return
+	}
+
+	if !assert.Error(t, v.ValidateAndDecode([]byte(`{`), &invalid), "ValidateAndDecode should fail on malformed JSON") {
+		return
+	}
+}
+
+func TestValidateTypedValues(t *testing.T) {
+	const src = `{
+  "type": "object",
+  "properties": {
+    "n": { "type": "integer", "minimum": 1 },
+    "f": { "type": "number" },
+    "s": { "type": "string" },
+    "b": { "type": "boolean" },
+    "m": {
+      "type": "object",
+      "properties": {
+        "u": { "type": "integer" }
+      }
+    }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	v := validator.New(s)
+
+	valid := map[string]interface{}{
+		"n": 5,
+		"f": float32(1.5),
+		"s": "x",
+		"b": true,
+		"m": map[string]interface{}{"u": uint8(1)},
+	}
+	if !assert.NoError(t, v.Validate(valid), "Validate should succeed for typed values") {
+		return
+	}
+
+	invalid := []map[string]interface{}{
+		{"n": 0},
+		{"n": "5"},
+		{"s": 1},
+		{"b": "true"},
+		{"m": map[string]interface{}{"u": "1"}},
+	}
+	for _, x := range invalid {
+		if !assert.Error(t, v.Validate(x), "Validate should fail for %#v", x) {
+			return
+		}
+	}
+}
+
+func TestValidateBytes(t *testing.T) {
+	s, err := schema.Read(strings.NewReader(`{"type":"object"}`))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	v := validator.New(s)
+
+	if !assert.NoError(t, v.ValidateBytes([]byte(" {\"a\":1}\n")), "ValidateBytes should succeed") {
+		return
+	}
+
+	malformed := []string{
+		`{"a":1} garbage`,
+		`{"a":1}{"b":2}`,
+		`{"a":1} 1`,
+		`{"a":1`,
+		``,
+	}
+	for _, data := range malformed {
+		if !assert.Error(t, v.ValidateBytes([]byte(data)), "ValidateBytes should fail for %q", data) {
+			return
+		}
+	}
+
+	// Top level scalars are documents too
+	s, err = schema.Read(strings.NewReader(`{}`))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	v = validator.New(s)
+
+	scalars := []string{
+		`1`,
+		`"s"`,
+		`null`,
+		`true`,
+		"1 \n\t",
+		" \"s\"\r\n",
+		"null\n\n",
+	}
+	for _, data := range scalars {
+		if !assert.NoError(t, v.ValidateBytes([]byte(data)), "ValidateBytes should succeed for %q", data) {
+			return
+		}
+	}
+
+	malformed = []string{
+		`1 2`,
+		`"s" x`,
+		`null null`,
+		`nul`,
+		`1.`,
+	}
+	for _, data := range malformed {
+		if !assert.Error(t, v.ValidateBytes([]byte(data)), "ValidateBytes should fail for %q", data) {
+			return
+		}
+	}
+}
+
+func TestValidateBytesScalar(t *testing.T) {
+	s, err := schema.Read(strings.NewReader(`{"type":"integer"}`))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	v := validator.New(s)
+
+	if !assert.NoError(t, v.ValidateBytes([]byte(`42`)), "integer should pass") {
+		return
+	}
+	for _, data := range []string{`"42"`, `4.2`, `true`, `null`} {
+		if !assert.Error(t, v.ValidateBytes([]byte(data)), "ValidateBytes should fail for %s", data) {
+			return
+		}
+	}
+}
+
+func TestUnsupportedKeywords(t *testing.T) {
+	tests := map[string]string{
+		"const":         `{"type":"object","properties":{"kind":{"const":"person"}}}`,
+		"contains":      `{"type":"array","contains":{"type":"integer"}}`,
+		"if":            `{"if":{"type":"string"},"then":{"minLength":1},"else":{"type":"integer"}}`,
+		"then":          `{"then":{"minLength":1}}`,
+		"else":          `{"else":{"type":"integer"}}`,
+		"propertyNames": `{"type":"object","propertyNames":{"pattern":"^[a-z]+$"}}`,
+		"$recursiveRef": `{"$recursiveAnchor":true,"type":"object","properties":{"child":{"$recursiveRef":"#"}}}`,
+		"$dynamicRef":   `{"$dynamicAnchor":"node","type":"object","properties":{"child":{"$dynamicRef":"#node"}}}`,
+	}
+	for keyword, src := range tests {
+		t.Logf("Testing %s", keyword)
+		s, err := schema.Read(strings.NewReader(src))
+		if !assert.NoError(t, err, "schema.Read should succeed") {
+			return
+		}
+		v := validator.New(s)
+		_, err = v.Compile()
+		if !assert.Error(t, err, "Compile should fail") {
+			return
+		}
+		if !assert.Contains(t, err.Error(), keyword, "error should name the keyword") {
+			return
+		}
+		if !assert.Error(t, v.Validate(map[string]interface{}{}), "Validate should fail") {
+			return
+		}
+	}
+}
+
+func TestUnsupportedKeywordsInRegistry(t *testing.T) {
+	const kinds = `{
+  "id": "http://example.com/kinds.json",
+  "definitions": {
+    "person": { "const": "person" }
+  }
+}`
+	const doc = `{
+  "id": "http://example.com/doc.json",
+  "type": "object",
+  "properties": {
+    "kind": { "$ref": "kinds.json#/definitions/person" }
+  }
+}`
+
+	r := schema.NewRegistry()
+	var s *schema.Schema
+	for _, src := range []string{kinds, doc} {
+		var err error
+		s, err = schema.Read(strings.NewReader(src))
+		if !assert.NoError(t, err, "schema.Read should succeed") {
+			return
+		}
+		if !assert.NoError(t, r.Add(s), "Registry.Add should succeed") {
+			return
+		}
+	}
+
+	_, err := validator.New(s).Compile()
+	if !assert.Error(t, err, "Compile should fail for keywords in referenced schemas") {
+		return
+	}
+	if !assert.Contains(t, err.Error(), "const", "error should name the keyword") {
+		return
+	}
+}
+
+func TestInvalidMultipleOf(t *testing.T) {
+	for _, n := range []float64{0, -2, math.NaN(), math.Inf(1)} {
+		s := schema.NewNumber()
+		s.MultipleOf = schema.Number{Val: n, Initialized: true}
+		if _, err := validator.New(s).Compile(); !assert.Error(t, err, "Compile should fail for multipleOf %v", n) {
+			return
+		}
+	}
+
+	s := schema.NewNumber()
+	s.MultipleOf = schema.Number{Val: 0.5, Initialized: true}
+	if _, err := validator.New(s).Compile(); !assert.NoError(t, err, "Compile should succeed for a positive multipleOf") {
+		return
+	}
+}
+
+func TestValidateRegistry(t *testing.T) {
+	const person = `{
+  "id": "http://example.com/person.json",
+  "definitions": {
+    "name": { "type": "string", "minLength": 1 }
+  }
+}`
+	const team = `{
+  "id": "http://example.com/team.json",
+  "type": "object",
+  "properties": {
+    "leader": { "$ref": "person.json#/definitions/name" }
+  },
+  "required": [ "leader" ]
+}`
+
+	r := schema.NewRegistry()
+	var s *schema.Schema
+	for _, src := range []string{person, team} {
+		var err error
+		s, err = schema.Read(strings.NewReader(src))
+		if !assert.NoError(t, err, "schema.Read should succeed") {
+			return
+		}
+		if !assert.NoError(t, r.Add(s), "Registry.Add should succeed") {
+			return
+		}
+	}
+
+	v := validator.New(s)
+	if !assert.NoError(t, v.ValidateBytes([]byte(`{"leader":"john"}`)), "valid document should pass") {
+		return
+	}
+	for _, doc := range []string{`{"leader":""}`, `{"leader":1}`} {
+		if !assert.Error(t, v.ValidateBytes([]byte(doc)), "invalid document should fail: %s", doc) {
+			return
+		}
+	}
+
+	s, err := schema.Read(strings.NewReader(`{ "properties": { "leader": { "$ref": "http://example.com/unknown.json" } } }`))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+	if _, err := validator.New(s).Compile(); !assert.Error(t, err, "Compile should fail for unresolvable references") {
+		return
+	}
+}
+
+func TestValidateAnchor(t *testing.T) {
+	const src = `{
+  "definitions": {
+    "name": { "$anchor": "name", "type": "string", "minLength": 1 }
+  },
+  "type": "object",
+  "properties": {
+    "first": { "$ref": "#name" }
+  }
+}`
+	s, err := schema.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	v := validator.New(s)
+	if !assert.NoError(t, v.ValidateBytes([]byte(`{"first":"john"}`)), "valid document should pass") {
+		return
+	}
+	for _, doc := range []string{`{"first":""}`, `{"first":1}`} {
+		if !assert.Error(t, v.ValidateBytes([]byte(doc)), "invalid document should fail: %s", doc) {
+			return
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Package yaml allows reading JSON Schemas written in YAML.
+// It lives in its own package so that users who only deal with
+// JSON do not need to depend on a YAML parser.
+package yaml
+
+import (
+	"io"
+	"io/ioutil"
+
+	"github.com/lestrrat/go-jsschema"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ReadFile reads the YAML file `f` and parses its content to
+// create a new Schema object
+func ReadFile(f string, options ...schema.ReadOption) (*schema.Schema, error) {
+	buf, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+	return parse(buf, options)
+}
+
+// Read reads YAML from `in` and parses its content to create
+// a new Schema object
+func Read(in io.Reader, options ...schema.ReadOption) (*schema.Schema, error) {
+	buf, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read YAML")
+	}
+	return parse(buf, options)
+}
+
+func parse(buf []byte, options []schema.ReadOption) (*schema.Schema, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(buf, &v); err != nil {
+		return nil, errors.Wrap(err, "failed to parse YAML")
+	}
+
+	v, err := convert(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert YAML")
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("invalid type: expected map[string]interface{}, got %T", v)
+	}
+
+	s := schema.New()
+	if err := s.Extract(m, options...); err != nil {
+		return nil, errors.Wrap(err, "failed to extract schema")
+	}
+	return s, nil
+}
+
+// convert turns the values produced by the YAML decoder into
+// the same shape encoding/json would produce: maps are keyed by
+// strings, and all numbers are float64. Maps with keys that are not
+// strings are rejected
+func convert(v interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			ks, ok := k.(string)
+			if !ok {
+				// JSON only has string keys, and stringifying keys such as
+				// `on` (a bool in YAML 1.1) would silently change them
+				return nil, errors.Errorf("invalid key %v: expected string, got %T (quote the key to use it as a string)", k, k)
+			}
+			ce, err := convert(e)
+			if err != nil {
+				return nil, err
+			}
+			m[ks] = ce
+		}
+		return m, nil
+	case []interface{}:
+		l := make([]interface{}, len(val))
+		for i, e := range val {
+			ce, err := convert(e)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = ce
+		}
+		return l, nil
+	case int:
+		return float64(val), nil
+	case int64:
+		return float64(val), nil
+	case uint64:
+		return float64(val), nil
+	case float32:
+		return float64(val), nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,139 @@
+package yaml_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lestrrat/go-jsschema"
+	"github.com/lestrrat/go-jsschema/validator"
+	"github.com/lestrrat/go-jsschema/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRead(t *testing.T) {
+	const src = `
+type: object
+required:
+  - name
+properties:
+  name:
+    type: string
+    minLength: 1
+  age:
+    type: integer
+    minimum: 0
+  tags:
+    type: array
+    items:
+      type: string
+patternProperties:
+  "^x-":
+    type: string
+`
+	const equivalent = `{
+  "type": "object",
+  "required": [ "name" ],
+  "properties": {
+    "name": { "type": "string", "minLength": 1 },
+    "age": { "type": "integer", "minimum": 0 },
+    "tags": { "type": "array", "items": { "type": "string" } }
+  },
+  "patternProperties": {
+    "^x-": { "type": "string" }
+  }
+}`
+
+	s, err := yaml.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "yaml.Read should succeed") {
+		return
+	}
+
+	if !assert.Equal(t, []string{"name"}, s.Required, "required should be parsed") {
+		return
+	}
+	if !assert.Equal(t, 1, s.Properties["name"].MinLength.Val, "minLength should be parsed") {
+		return
+	}
+	if !assert.True(t, s.Properties["age"].Minimum.Initialized, "minimum should be parsed") {
+		return
+	}
+
+	js, err := schema.Read(strings.NewReader(equivalent))
+	if !assert.NoError(t, err, "schema.Read should succeed") {
+		return
+	}
+
+	fromYAML, err := json.Marshal(s)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	fromJSON, err := json.Marshal(js)
+	if !assert.NoError(t, err, "json.Marshal should succeed") {
+		return
+	}
+	if !assert.Equal(t, string(fromJSON), string(fromYAML), "YAML and JSON schemas should be equivalent") {
+		return
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	for _, src := range []string{"- a\n- b\n", "type: 1\n", "{", "properties:\n  200:\n    type: string\n", "properties:\n  on:\n    type: string\n"} {
+		_, err := yaml.Read(strings.NewReader(src))
+		if !assert.Error(t, err, "yaml.Read should fail for %q", src) {
+			return
+		}
+	}
+}
+
+func TestReadQuotedKeys(t *testing.T) {
+	const src = `
+properties:
+  "200":
+    type: string
+  "on":
+    type: boolean
+`
+	s, err := yaml.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "yaml.Read should succeed") {
+		return
+	}
+	if !assert.Len(t, s.Properties, 2, "quoted keys should be parsed as strings") {
+		return
+	}
+	if !assert.Contains(t, s.Properties, "200", "quoted number should be a property name") {
+		return
+	}
+	if !assert.Contains(t, s.Properties, "on", "quoted bool should be a property name") {
+		return
+	}
+}
+
+func TestValidate(t *testing.T) {
+	const src = `
+type: object
+required:
+  - name
+properties:
+  name:
+    type: string
+    minLength: 1
+  age:
+    type: integer
+    minimum: 0
+`
+	s, err := yaml.Read(strings.NewReader(src))
+	if !assert.NoError(t, err, "yaml.Read should succeed") {
+		return
+	}
+	v := validator.New(s)
+
+	if !assert.NoError(t, v.ValidateBytes([]byte(`{"name":"john","age":42}`)), "valid document should pass") {
+		return
+	}
+	for _, doc := range []string{`{"age":42}`, `{"name":""}`, `{"name":"john","age":-1}`, `{"name":"john","age":1.5}`} {
+		if !assert.Error(t, v.ValidateBytes([]byte(doc)), "invalid document should fail: %s", doc) {
+			return
+		}
+	}
+}